@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/types"
+)
+
+// inputChunkBytes is the size above which input read from a file or pipe is
+// sent to the server in several requests.
+const inputChunkBytes = 8 * 1024
+
+// errInputCanceled is returned by sendInput when the server canceled the
+// input, as -stop does.
+var errInputCanceled = errors.New("input canceled")
+
+// speakInput sends the text of -input, the file of -file or standard input
+// to the server with the overrides of the command line.
+func speakInput(client *http.Client, state types.AppState) error {
+	speechReq, err := inputRequest(state)
+	if err != nil {
+		return err
+	}
+	send := func(text string) error {
+		return sendInput(client, state.ClientPort, speechReq, text)
+	}
+
+	switch state.ClientFile {
+	case "":
+		return send(state.ClientInput)
+	case "-":
+		return streamInput(os.Stdin, speechReq.Markdown, send)
+	default:
+		return sendFile(state.ClientFile, send)
+	}
+}
+
+// sendFile speaks a file, sending it in chunks that end at blank lines.
+func sendFile(path string, send func(text string) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+
+	for _, chunk := range splitInput(string(data), inputChunkBytes) {
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitInput splits text into chunks that end at the first blank line
+// outside fenced code after limit bytes, so neither paragraphs nor markdown
+// blocks are cut. Text without blank lines is cut at a line break once a
+// chunk reaches four times the limit.
+func splitInput(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	var blocks markdownBlocks
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			chunks = append(chunks, current.String())
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		current.WriteString(line)
+		complete := blocks.add(line)
+		if (complete && current.Len() >= limit) || current.Len() >= 4*limit {
+			flush()
+		}
+	}
+	flush()
+	return chunks
+}
+
+// streamInput sends each line read from r as soon as it arrives, so
+// "tail -f build.log | voxctl -" follows the log. Lines that are already
+// waiting, as when a file is piped in, are sent together in chunks of about
+// inputChunkBytes. Markdown is only sent at a blank line outside fenced
+// code, so its blocks stay whole. Input canceled by -stop is skipped, so
+// following goes on with the next lines.
+func streamInput(r io.Reader, markdown bool, send func(text string) error) error {
+	lines := make(chan string, 1024)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				lines <- line
+			}
+			if err != nil {
+				if err != io.EOF {
+					readErr <- fmt.Errorf("failed to read standard input: %v", err)
+				}
+				return
+			}
+		}
+	}()
+
+	var blocks markdownBlocks
+	for line := range lines {
+		var batch strings.Builder
+		batch.WriteString(line)
+		complete := !markdown || blocks.add(line)
+
+	collect:
+		for batch.Len() < inputChunkBytes || !complete {
+			var next string
+			var ok bool
+			if complete {
+				// Don't wait for more input if the batch can be spoken
+				select {
+				case next, ok = <-lines:
+				default:
+					break collect
+				}
+			} else {
+				next, ok = <-lines
+			}
+			if !ok {
+				break
+			}
+			batch.WriteString(next)
+			complete = !markdown || blocks.add(next)
+		}
+
+		if strings.TrimSpace(batch.String()) == "" {
+			continue
+		}
+		if err := send(batch.String()); err != nil {
+			if errors.Is(err, errInputCanceled) {
+				log.Info("Input canceled, waiting for more")
+				continue
+			}
+			return err
+		}
+	}
+
+	select {
+	case err := <-readErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// markdownBlocks follows the lines of a markdown document to tell where a
+// block ends.
+type markdownBlocks struct {
+	fence string
+}
+
+// add reports whether line ends a block, which is a blank line outside
+// fenced code.
+func (b *markdownBlocks) add(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, fence := range []string{"```", "~~~"} {
+		if !strings.HasPrefix(trimmed, fence) {
+			continue
+		}
+		if b.fence == "" {
+			b.fence = fence
+		} else if b.fence == fence {
+			b.fence = ""
+		}
+	}
+	return trimmed == "" && b.fence == ""
+}
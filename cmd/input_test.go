@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "under the limit",
+			text:  "One.\n\nTwo.\n",
+			limit: 100,
+			want:  []string{"One.\n\nTwo.\n"},
+		},
+		{
+			name:  "at blank lines",
+			text:  "First paragraph.\n\nSecond paragraph.\n\nThird.\n",
+			limit: 10,
+			want:  []string{"First paragraph.\n\n", "Second paragraph.\n\n", "Third.\n"},
+		},
+		{
+			name:  "after the limit",
+			text:  "a\n\nb\n\nc\n\nd\n",
+			limit: 5,
+			want:  []string{"a\n\nb\n\n", "c\n\nd\n"},
+		},
+		{
+			name:  "fenced code stays whole",
+			text:  "Text.\n\n```\ncode\n\nmore\n```\n\nAfter.\n",
+			limit: 6,
+			want:  []string{"Text.\n\n", "```\ncode\n\nmore\n```\n\n", "After.\n"},
+		},
+		{
+			name:  "no blank lines",
+			text:  "line one\nline two\nline three\n",
+			limit: 4,
+			want:  []string{"line one\nline two\n", "line three\n"},
+		},
+		{
+			name:  "blank input",
+			text:  "\n\n  \n",
+			limit: 4,
+			want:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := splitInput(test.text, test.limit); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("splitInput(%q, %d) = %q, want %q", test.text, test.limit, got, test.want)
+			}
+		})
+	}
+}
+
+func TestStreamInputBatches(t *testing.T) {
+	input := "# Title\n\n```\nls\n\nls -la\n```\n\nLast line.\n"
+	for _, markdown := range []bool{false, true} {
+		var sent []string
+		err := streamInput(strings.NewReader(input), markdown, func(text string) error {
+			sent = append(sent, text)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("streamInput() failed: %v", err)
+		}
+		if got := strings.Join(sent, ""); strings.TrimSpace(got) != strings.TrimSpace(input) {
+			t.Errorf("streamInput(markdown=%v) sent %q, want all of %q", markdown, got, input)
+		}
+		for _, text := range sent {
+			if !strings.HasSuffix(text, "\n") {
+				t.Errorf("streamInput(markdown=%v) sent %q, which doesn't end a line", markdown, text)
+			}
+			if markdown && strings.Count(text, "```")%2 != 0 {
+				t.Errorf("streamInput() split a code block: %q", text)
+			}
+		}
+	}
+}
+
+func TestStreamInputFollows(t *testing.T) {
+	reader, writer := io.Pipe()
+	sent := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- streamInput(reader, false, func(text string) error {
+			sent <- text
+			return nil
+		})
+	}()
+
+	writer.Write([]byte("first\n"))
+	select {
+	case text := <-sent:
+		if text != "first\n" {
+			t.Errorf("streamInput() sent %q, want %q", text, "first\n")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("streamInput() waited for more input before sending a line")
+	}
+
+	writer.CloseWithError(errors.New("broken pipe"))
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+			t.Errorf("streamInput() = %v, want the read error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("streamInput() didn't return after the input ended")
+	}
+}
+
+func TestStreamInputSendError(t *testing.T) {
+	failure := errors.New("server rejected input")
+	err := streamInput(strings.NewReader("one\n"), false, func(string) error {
+		return failure
+	})
+	if err != failure {
+		t.Errorf("streamInput() = %v, want %v", err, failure)
+	}
+}
+
+func TestStreamInputCanceled(t *testing.T) {
+	reader, writer := io.Pipe()
+	sent := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- streamInput(reader, false, func(text string) error {
+			sent <- text
+			if text == "first\n" {
+				return fmt.Errorf("%w: request canceled (canceled)", errInputCanceled)
+			}
+			return nil
+		})
+	}()
+
+	// A line canceled by -stop doesn't end following
+	for _, line := range []string{"first\n", "second\n"} {
+		writer.Write([]byte(line))
+		select {
+		case text := <-sent:
+			if text != line {
+				t.Errorf("streamInput() sent %q, want %q", text, line)
+			}
+		case err := <-done:
+			t.Fatalf("streamInput() = %v after sending %q, want it to go on", err, line)
+		case <-time.After(time.Second):
+			t.Fatalf("streamInput() didn't send %q", line)
+		}
+	}
+
+	writer.Close()
+	if err := <-done; err != nil {
+		t.Errorf("streamInput() = %v, want nil", err)
+	}
+}
@@ -2,23 +2,44 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	stdlog "log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/log"
+	_ "github.com/ln64-git/voxctl/external/azure"
+	_ "github.com/ln64-git/voxctl/external/command"
+	_ "github.com/ln64-git/voxctl/external/elevenLabs"
+	_ "github.com/ln64-git/voxctl/external/google"
+	_ "github.com/ln64-git/voxctl/external/openai"
+	_ "github.com/ln64-git/voxctl/external/polly"
 	"github.com/ln64-git/voxctl/internal/audio"
+	"github.com/ln64-git/voxctl/internal/cache"
 	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/secret"
 	"github.com/ln64-git/voxctl/internal/server"
 	"github.com/ln64-git/voxctl/internal/speech"
 	"github.com/ln64-git/voxctl/internal/types"
 )
 
 func main() {
+	// Keep credentials out of the logs, including those of libraries
+	log.SetOutput(secret.NewRedactingWriter(os.Stderr))
+	stdlog.SetOutput(secret.NewRedactingWriter(os.Stderr))
+
 	flagsConfig := parseFlags()
 	settingsConfig := config.GetConfig()
 
@@ -59,17 +80,86 @@ func processRequest(state types.AppState) {
 		}
 		defer resp.Body.Close()
 
-	case state.ClientInput != "":
-		log.Infof("Sending input: %s", state.ClientInput)
-		speechReq := speech.SpeechRequest{
-			Text: state.ClientInput,
+	case state.ServerVoicesRequested:
+		query := url.Values{}
+		query.Set("provider", state.ClientProvider)
+		query.Set("language", state.ClientLanguage)
+		query.Set("gender", state.ClientGender)
+		resp, err := client.Get(fmt.Sprintf("http://localhost:%d/voices?%s", state.ClientPort, query.Encode()))
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Errorf("Failed to list voices: %s", readServerError(resp))
+			return
+		}
+
+		var voices []speech.Voice
+		if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
+			log.Errorf("Failed to decode voices: %v", err)
+			return
+		}
+		printVoices(voices)
+
+	case state.ServerUsageRequested:
+		resp, err := client.Get(fmt.Sprintf("http://localhost:%d/usage", state.ClientPort))
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Errorf("Failed to read usage: %s", readServerError(resp))
+			return
+		}
+
+		var reports []speech.UsageReport
+		if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+			log.Errorf("Failed to decode usage: %v", err)
+			return
+		}
+		printUsage(reports)
+
+	case state.ServerCacheCommand == "stats":
+		resp, err := client.Get(fmt.Sprintf("http://localhost:%d/cache/stats", state.ClientPort))
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Errorf("Failed to read cache stats: %s", readServerError(resp))
+			return
+		}
+
+		var stats cache.Stats
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			log.Errorf("Failed to decode cache stats: %v", err)
+			return
 		}
-		body := bytes.NewBufferString(speechReq.SpeechRequestToJSON())
-		resp, err := client.Post(fmt.Sprintf("http://localhost:%d/input", state.ClientPort), "application/json", body)
+		printCacheStats(stats)
+
+	case state.ServerCacheCommand == "clear":
+		resp, err := client.Post(fmt.Sprintf("http://localhost:%d/cache/clear", state.ClientPort), "", nil)
 		if err != nil {
 			return
 		}
 		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Errorf("Failed to clear cache: %s", readServerError(resp))
+			return
+		}
+		log.Info("Audio cache cleared")
+
+	case state.ServerCacheCommand != "":
+		log.Errorf("Unknown cache command %q (expected stats or clear)", state.ServerCacheCommand)
+
+	case state.ClientFile != "" && state.ClientInput != "":
+		log.Errorf("Use either -input or -file, not both")
+
+	case state.ClientFile != "" || state.ClientInput != "":
+		if err := speakInput(client, state); err != nil {
+			log.Errorf("%v", err)
+		}
 
 	case state.ServerPauseRequested:
 		resp, err := client.Post(fmt.Sprintf("http://localhost:%d/pause", state.ClientPort), "", nil)
@@ -87,20 +177,137 @@ func processRequest(state types.AppState) {
 	}
 }
 
+// inputRequest builds the speech request for the voice overrides of the
+// command line, without its text.
+func inputRequest(state types.AppState) (speech.SpeechRequest, error) {
+	var normalize *bool
+	if state.ClientNormalize != "" {
+		enabled, err := strconv.ParseBool(state.ClientNormalize)
+		if err != nil {
+			return speech.SpeechRequest{}, fmt.Errorf("invalid -normalize value %q (expected true or false)", state.ClientNormalize)
+		}
+		normalize = &enabled
+	}
+	return speech.SpeechRequest{
+		Provider:  state.ClientProvider,
+		Voice:     state.ClientVoice,
+		Language:  state.ClientLanguage,
+		Gender:    state.ClientGender,
+		Speed:     state.ClientSpeed,
+		Normalize: normalize,
+		Markdown:  state.ClientMarkdown,
+	}, nil
+}
+
+// sendInput asks the server to speak text with the overrides of speechReq,
+// returning once it has been synthesized. Input canceled by -stop fails
+// with errInputCanceled.
+func sendInput(client *http.Client, port int, speechReq speech.SpeechRequest, text string) error {
+	log.Infof("Sending input of %d characters", utf8.RuneCountInString(text))
+	speechReq.Text = text
+	body := bytes.NewBufferString(speechReq.SpeechRequestToJSON())
+	resp, err := client.Post(fmt.Sprintf("http://localhost:%d/input", port), "application/json", body)
+	if err != nil {
+		return fmt.Errorf("failed to send input: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == server.StatusClientClosedRequest {
+		return fmt.Errorf("%w: %s", errInputCanceled, readServerError(resp))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server rejected input: %s", readServerError(resp))
+	}
+	return nil
+}
+
+// readServerError extracts the message and category from an error
+// response, falling back to the raw body.
+func readServerError(resp *http.Response) string {
+	errorBody, _ := io.ReadAll(resp.Body)
+
+	var errorResponse server.ErrorResponse
+	if err := json.Unmarshal(errorBody, &errorResponse); err == nil && errorResponse.Error != "" {
+		return fmt.Sprintf("%s (%s)", errorResponse.Error, errorResponse.Category)
+	}
+	return strings.TrimSpace(string(errorBody))
+}
+
+func printVoices(voices []speech.Voice) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PROVIDER\tID\tNAME\tLANGUAGE\tGENDER\tSTYLES")
+	for _, voice := range voices {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", voice.Provider, voice.ID, voice.Name, voice.Language, voice.Gender, strings.Join(voice.Styles, ", "))
+	}
+	writer.Flush()
+}
+
+func printUsage(reports []speech.UsageReport) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PROVIDER\tTODAY\tDAILY BUDGET\tMONTH\tMONTHLY BUDGET")
+	for _, report := range reports {
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%d\t%s\n", report.Provider, report.Today, formatBudget(report.DailyBudget), report.Month, formatBudget(report.MonthlyBudget))
+	}
+	writer.Flush()
+}
+
+func formatBudget(limit int64) string {
+	if limit == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+func printCacheStats(stats cache.Stats) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Directory:\t%s\n", stats.Dir)
+	fmt.Fprintf(writer, "Entries:\t%d\n", stats.Entries)
+	fmt.Fprintf(writer, "Size:\t%.1f MB of %.1f MB\n", float64(stats.Bytes)/(1024*1024), float64(stats.MaxBytes)/(1024*1024))
+	fmt.Fprintf(writer, "Hits:\t%d\n", stats.Hits)
+	fmt.Fprintf(writer, "Misses:\t%d\n", stats.Misses)
+	writer.Flush()
+}
+
 func parseFlags() types.AppState {
 	clientPort := flag.Int("port", 8080, "Port number to connect or serve")
 	clientInput := flag.String("input", "", "Input text to play")
+	clientFile := flag.String("file", "", "File to play, or - to stream standard input line by line")
+	clientProvider := flag.String("provider", "", "Voice service to use for this input")
+	clientVoice := flag.String("voice", "", "Voice to use for this input")
+	clientLanguage := flag.String("language", "", "Language of this input, e.g. en-US")
+	clientGender := flag.String("gender", "", "Voice gender for this input (male, female or neutral)")
+	clientSpeed := flag.Float64("speed", 0, "Speaking rate multiplier for this input (0.25 to 4.0)")
+	clientMarkdown := flag.Bool("markdown", false, "Read this input as markdown")
+	clientNormalize := flag.String("normalize", "", "Expand numbers, dates and units into words for this input (true or false)")
 	serverStatusRequested := flag.Bool("status", false, "Request info")
+	serverVoicesRequested := flag.Bool("voices", false, "List available voices, filtered by -provider, -language and -gender")
+	serverUsageRequested := flag.Bool("usage", false, "Show characters sent to each provider")
+	serverCacheCommand := flag.String("cache", "", "Audio cache command: stats or clear")
 	serverQuitRequested := flag.Bool("quit", false, "Exit application after request")
 	serverPauseRequested := flag.Bool("pause", false, "Pause audio playback")
 	serverStopRequested := flag.Bool("stop", false, "Stop audio playback")
 
 	flag.Parse()
+	if flag.Arg(0) == "-" {
+		// Parsing stops at "-", so read the flags after it too
+		*clientFile = "-"
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
 
 	return types.AppState{
 		ClientPort:            *clientPort,
 		ClientInput:           *clientInput,
+		ClientFile:            *clientFile,
+		ClientProvider:        *clientProvider,
+		ClientVoice:           *clientVoice,
+		ClientLanguage:        *clientLanguage,
+		ClientGender:          *clientGender,
+		ClientSpeed:           *clientSpeed,
+		ClientNormalize:       *clientNormalize,
+		ClientMarkdown:        *clientMarkdown,
 		ServerStatusRequested: *serverStatusRequested,
+		ServerVoicesRequested: *serverVoicesRequested,
+		ServerCacheCommand:    *serverCacheCommand,
+		ServerUsageRequested:  *serverUsageRequested,
 		ServerQuitRequested:   *serverQuitRequested,
 		ServerPauseRequested:  *serverPauseRequested,
 		ServerStopRequested:   *serverStopRequested,
@@ -109,21 +316,11 @@ func parseFlags() types.AppState {
 
 func initializeAppState(state *types.AppState, configData map[string]interface{}) {
 	state.VoiceService = config.GetStringOrDefault(configData, "VoiceService", "")
-	state.ElevenLabsSubscriptionKey = config.GetStringOrDefault(configData, "ElevenLabsSubscriptionKey", "")
-	state.ElevenLabsVoiceModelID = config.GetStringOrDefault(configData, "ElevenLabsVoiceModelID", "eleven_monolingual_v1")
-	state.ElevenLabsVoiceStability = config.GetFloat64OrDefault(configData, "ElevenLabsVoiceStability", 0.5)
-	state.ElevenLabsVoiceSimilarityBoost = config.GetFloat64OrDefault(configData, "ElevenLabsVoiceSimilarityBoost", 0.5)
-	state.ElevenLabsVoiceStyle = config.GetFloat64OrDefault(configData, "ElevenLabsVoiceStyle", 0.5)
-	state.ElevenLabsVoiceUseSpeakerBoost = config.GetBoolOrDefault(configData, "ElevenLabsVoiceUseSpeakerBoost", false)
-
-	state.AzureSubscriptionKey = config.GetStringOrDefault(configData, "AzureSubscriptionKey", "")
-	state.AzureRegion = config.GetStringOrDefault(configData, "AzureRegion", "eastus")
-	state.AzureVoiceGender = config.GetStringOrDefault(configData, "AzureVoiceGender", "Female")
-	state.AzureVoiceName = config.GetStringOrDefault(configData, "AzureVoiceName", "en-US-JennyNeural")
-
-	state.GoogleSubscriptionKey = config.GetStringOrDefault(configData, "GoogleSubscriptionKey", "")
-	state.GoogleLanguageCode = config.GetStringOrDefault(configData, "GoogleLanguageCode", "en-US")
-	state.GoogleVoiceName = config.GetStringOrDefault(configData, "GoogleVoiceName", "en-US-Wavenet-D")
+	state.Config = configData
+
+	if err := httpclient.Configure(configData); err != nil {
+		log.Errorf("Failed to configure HTTP client, using defaults: %v", err)
+	}
 
 	state.ServerAlreadyRunning = server.CheckServerRunning(state.ClientPort)
 }
@@ -0,0 +1,185 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	tokenEndpoint = "https://%s.api.cognitive.microsoft.com" + tokenPath
+	tokenPath     = "/sts/v1.0/issueToken"
+
+	// tokenLifetime applies when a token's expiry can't be read from it;
+	// Azure issues tokens valid for ten minutes
+	tokenLifetime = 10 * time.Minute
+	// tokenRefreshMargin renews tokens this long before they expire
+	tokenRefreshMargin = time.Minute
+)
+
+// Credentials authorize requests to the Speech service.
+type Credentials interface {
+	Authorize(ctx context.Context, req *http.Request) error
+}
+
+// SubscriptionKey sends the resource key with every request.
+type SubscriptionKey string
+
+func (k SubscriptionKey) Authorize(ctx context.Context, req *http.Request) error {
+	req.Header.Set("Ocp-Apim-Subscription-Key", string(k))
+	return nil
+}
+
+// noCredentials is used for endpoints that need no authentication, such as
+// Speech containers.
+type noCredentials struct{}
+
+func (noCredentials) Authorize(ctx context.Context, req *http.Request) error {
+	return nil
+}
+
+// TokenIssuer exchanges the resource key for short-lived bearer tokens, so
+// the key itself is only sent to the token endpoint. Tokens are cached and
+// renewed a minute before they expire.
+type TokenIssuer struct {
+	subscriptionKey string
+	tokenURL        string
+
+	mutex       sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+var (
+	tokenIssuersMutex sync.Mutex
+	tokenIssuers      = make(map[string]*TokenIssuer)
+)
+
+// NewTokenIssuer returns the issuer for a key and token endpoint. Issuers
+// are shared, so their tokens survive between requests.
+func NewTokenIssuer(subscriptionKey, tokenURL string) *TokenIssuer {
+	tokenIssuersMutex.Lock()
+	defer tokenIssuersMutex.Unlock()
+
+	cacheKey := subscriptionKey + "\x00" + tokenURL
+	if issuer, ok := tokenIssuers[cacheKey]; ok {
+		return issuer
+	}
+	issuer := &TokenIssuer{subscriptionKey: subscriptionKey, tokenURL: tokenURL}
+	tokenIssuers[cacheKey] = issuer
+	return issuer
+}
+
+func (t *TokenIssuer) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := t.token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (t *TokenIssuer) token(ctx context.Context) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	if t.accessToken != "" && now.Add(tokenRefreshMargin).Before(t.expiry) {
+		return t.accessToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", t.subscriptionKey)
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", speech.ResponseError(resp)
+	}
+
+	tokenData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %v", err)
+	}
+	token := strings.TrimSpace(string(tokenData))
+	if token == "" {
+		return "", fmt.Errorf("token response is empty")
+	}
+
+	t.accessToken = token
+	t.expiry = tokenExpiry(token, now)
+	return t.accessToken, nil
+}
+
+// invalidate drops the cached token if it is the one sent in authorization,
+// so the next request fetches a new one.
+func (t *TokenIssuer) invalidate(authorization string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.accessToken != "" && authorization == "Bearer "+t.accessToken {
+		t.accessToken = ""
+	}
+}
+
+// send authorizes and sends the request built by newRequest. A token the
+// service rejects with 401, such as one revoked before it expired, is
+// dropped and the request sent once more with a new one.
+func send(ctx context.Context, credentials Credentials, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		if err := credentials.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+
+		client := httpclient.Client()
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, speech.RequestError(err)
+		}
+
+		issuer, usesTokens := credentials.(*TokenIssuer)
+		if resp.StatusCode != http.StatusUnauthorized || !usesTokens || attempt > 0 {
+			return resp, nil
+		}
+		resp.Body.Close()
+		issuer.invalidate(req.Header.Get("Authorization"))
+	}
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it, falling
+// back to the documented token lifetime.
+func tokenExpiry(token string, issuedAt time.Time) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Exp int64 `json:"exp"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+				return time.Unix(claims.Exp, 0)
+			}
+		}
+	}
+	return issuedAt.Add(tokenLifetime)
+}
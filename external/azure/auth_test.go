@@ -0,0 +1,203 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+// speechServer stands in for a Speech resource with a custom domain, which
+// issues tokens on the same host. Revoking makes it reject the tokens issued
+// so far.
+type speechServer struct {
+	*httptest.Server
+	mutex         sync.Mutex
+	tokenRequests int
+	valid         map[string]bool
+}
+
+func newSpeechServer(t *testing.T) *speechServer {
+	s := &speechServer{valid: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		switch r.URL.Path {
+		case tokenPath:
+			if r.Header.Get("Ocp-Apim-Subscription-Key") != "resource-key" {
+				http.Error(w, "bad key", http.StatusUnauthorized)
+				return
+			}
+			s.tokenRequests++
+			claims := fmt.Sprintf(`{"exp":%d,"n":%d}`, time.Now().Add(10*time.Minute).Unix(), s.tokenRequests)
+			token := "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+			s.valid[token] = true
+			fmt.Fprint(w, token)
+		case synthesizePath:
+			token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !s.valid[token] || r.Header.Get("Ocp-Apim-Subscription-Key") != "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, "RIFF audio")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *speechServer) revoke() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.valid = make(map[string]bool)
+}
+
+func (s *speechServer) issued() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.tokenRequests
+}
+
+func TestTokenCredentials(t *testing.T) {
+	server := newSpeechServer(t)
+	synthesizer, err := newSynthesizer(map[string]interface{}{
+		"AzureSubscriptionKey": "resource-key",
+		"AzureEndpoint":        server.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	speak := func() {
+		t.Helper()
+		audio, err := synthesizer.Synthesize(context.Background(), "Hello.", speech.SynthesisOptions{})
+		if err != nil || string(audio.Data) != "RIFF audio" {
+			t.Fatalf("Synthesize() = %q, %v", audio.Data, err)
+		}
+	}
+
+	// The token comes from the custom endpoint and is reused
+	speak()
+	speak()
+	if got := server.issued(); got != 1 {
+		t.Errorf("issued %d tokens, want 1", got)
+	}
+
+	// A revoked token is dropped and the request retried with a new one
+	server.revoke()
+	speak()
+	if got := server.issued(); got != 2 {
+		t.Errorf("issued %d tokens, want 2", got)
+	}
+}
+
+func TestRejectedKey(t *testing.T) {
+	server := newSpeechServer(t)
+	synthesizer, err := newSynthesizer(map[string]interface{}{
+		"AzureSubscriptionKey": "wrong-key",
+		"AzureEndpoint":        server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = synthesizer.Synthesize(context.Background(), "Hello.", speech.SynthesisOptions{})
+	if speech.ErrorCategory(err) != string(speech.ErrorAuth) {
+		t.Errorf("Synthesize() error = %v, want auth", err)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          map[string]interface{}
+		wantEndpoint string
+		wantTokenURL string
+		want         Credentials
+	}{
+		{
+			name:         "regional tokens",
+			cfg:          map[string]interface{}{"AzureSubscriptionKey": "key", "AzureRegion": "westeurope"},
+			wantEndpoint: "https://westeurope.tts.speech.microsoft.com",
+			wantTokenURL: "https://westeurope.api.cognitive.microsoft.com/sts/v1.0/issueToken",
+		},
+		{
+			name:         "custom domain tokens",
+			cfg:          map[string]interface{}{"AzureSubscriptionKey": "key", "AzureEndpoint": "https://voice.example.com/"},
+			wantEndpoint: "https://voice.example.com",
+			wantTokenURL: "https://voice.example.com/sts/v1.0/issueToken",
+		},
+		{
+			name:         "explicit token URL",
+			cfg:          map[string]interface{}{"AzureSubscriptionKey": "key", "AzureTokenURL": "https://sts.example.com/token"},
+			wantEndpoint: "https://eastus.tts.speech.microsoft.com",
+			wantTokenURL: "https://sts.example.com/token",
+		},
+		{
+			name:         "key without tokens",
+			cfg:          map[string]interface{}{"AzureSubscriptionKey": "key", "AzureUseTokens": false},
+			wantEndpoint: "https://eastus.tts.speech.microsoft.com",
+			want:         SubscriptionKey("key"),
+		},
+		{
+			name:         "container without a key",
+			cfg:          map[string]interface{}{"AzureEndpoint": "http://localhost:5000"},
+			wantEndpoint: "http://localhost:5000",
+			want:         noCredentials{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := newSynthesizer(test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := provider.(*synthesizer)
+			if s.endpoint != test.wantEndpoint {
+				t.Errorf("endpoint = %s, want %s", s.endpoint, test.wantEndpoint)
+			}
+			if test.wantTokenURL != "" {
+				issuer, ok := s.credentials.(*TokenIssuer)
+				if !ok || issuer.tokenURL != test.wantTokenURL {
+					t.Errorf("credentials = %#v, want tokens from %s", s.credentials, test.wantTokenURL)
+				}
+			} else if s.credentials != test.want {
+				t.Errorf("credentials = %#v, want %#v", s.credentials, test.want)
+			}
+		})
+	}
+
+	if _, err := newSynthesizer(map[string]interface{}{}); err == nil {
+		t.Error("newSynthesizer() without a key or endpoint succeeded")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	issuedAt := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	exp := issuedAt.Add(9 * time.Minute)
+	jwt := "e30." + base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+
+	tests := []struct {
+		token string
+		want  time.Time
+	}{
+		{jwt, exp},
+		{"opaque-token", issuedAt.Add(tokenLifetime)},
+		{"a.not base64.c", issuedAt.Add(tokenLifetime)},
+		{"e30.e30.sig", issuedAt.Add(tokenLifetime)},
+	}
+	for _, test := range tests {
+		if got := tokenExpiry(test.token, issuedAt); !got.Equal(test.want) {
+			t.Errorf("tokenExpiry(%q) = %s, want %s", test.token, got, test.want)
+		}
+	}
+}
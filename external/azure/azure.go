@@ -2,43 +2,50 @@ package azure
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+	"github.com/ln64-git/voxctl/internal/ssml"
 )
 
 const (
-	apiEndpoint = "https://%s.tts.speech.microsoft.com/cognitiveservices/v1"
-)
+	regionalEndpoint = "https://%s.tts.speech.microsoft.com"
+	synthesizePath   = "/cognitiveservices/v1"
 
-func SynthesizeSpeech(subscriptionKey, region, text, voiceGender, voiceName string) ([]byte, error) {
-	ssml := generateSSML(text, voiceGender, voiceName)
+	// maxInputBytes keeps requests well below the ten minutes of audio Azure
+	// returns per request, even at slow speaking rates, and the 64 KB SSML limit
+	maxInputBytes = 4000
+)
 
-	url := fmt.Sprintf(apiEndpoint, region)
+// SynthesizeSpeech sends an SSML document to the Speech endpoint, such as
+// https://eastus.tts.speech.microsoft.com, and returns the WAV audio.
+func SynthesizeSpeech(ctx context.Context, endpoint string, credentials Credentials, document string) ([]byte, error) {
+	url := endpoint + synthesizePath
 	headers := map[string]string{
-		"Ocp-Apim-Subscription-Key": subscriptionKey,
-		"Content-Type":              "application/ssml+xml",
-		"X-Microsoft-OutputFormat":  "riff-48khz-16bit-mono-pcm",
+		"Content-Type":             "application/ssml+xml",
+		"X-Microsoft-OutputFormat": "riff-48khz-16bit-mono-pcm",
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(ssml)))
+	resp, err := send(ctx, credentials, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(document))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status: %s", resp.Status)
+		return nil, speech.ResponseError(resp)
 	}
 
 	audioData, err := io.ReadAll(resp.Body)
@@ -49,10 +56,21 @@ func SynthesizeSpeech(subscriptionKey, region, text, voiceGender, voiceName stri
 	return audioData, nil
 }
 
-func generateSSML(text, voiceGender, voiceName string) string {
-	return fmt.Sprintf(`<speak version='1.0' xml:lang='en-US'>
-                            <voice xml:lang='en-US' xml:gender='%s' name='%s'>
-                                %s
-                            </voice>
-                        </speak>`, voiceGender, voiceName, text)
+// generateSSML wraps text in a speak document for the given voice. Unless
+// set explicitly, the language is taken from the voice name, falling back
+// to en-US.
+func generateSSML(text string, voice ssml.VoiceOptions, prosody ssml.ProsodyOptions) string {
+	if voice.Lang == "" {
+		voice.Lang = speech.LanguageFromVoiceName(voice.Name)
+	}
+	if voice.Lang == "" {
+		voice.Lang = "en-US"
+	}
+
+	content := ssml.Text(text)
+	if !prosody.IsZero() {
+		content = ssml.Prosody(prosody, content)
+	}
+
+	return ssml.Speak(voice.Lang, ssml.Voice(voice, content)).String()
 }
@@ -0,0 +1,39 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+func TestGenerateSSML(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		voice   ssml.VoiceOptions
+		prosody ssml.ProsodyOptions
+		want    string
+	}{
+		{
+			name:  "language from the voice name",
+			text:  "Fish & chips <today>",
+			voice: ssml.VoiceOptions{Name: "en-GB-SoniaNeural"},
+			want:  `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="en-GB"><voice xml:lang="en-GB" name="en-GB-SoniaNeural">Fish &amp; chips &lt;today&gt;</voice></speak>`,
+		},
+		{
+			name:    "prosody and gender",
+			text:    `"Hi"`,
+			voice:   ssml.VoiceOptions{Name: "custom", Gender: "Female"},
+			prosody: ssml.ProsodyOptions{Rate: "+10%"},
+			want:    `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="en-US"><voice xml:lang="en-US" xml:gender="Female" name="custom"><prosody rate="+10%">&#34;Hi&#34;</prosody></voice></speak>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := generateSSML(test.text, test.voice, test.prosody); got != test.want {
+				t.Errorf("generateSSML() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+func init() {
+	speech.Register("Azure", newSynthesizer)
+}
+
+type synthesizer struct {
+	endpoint    string
+	credentials Credentials
+	voiceGender string
+	voiceName   string
+	prosody     ssml.ProsodyOptions
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	subscriptionKey, err := secret.Get(cfg, "AzureSubscriptionKey")
+	if err != nil {
+		return nil, err
+	}
+
+	region := config.GetStringOrDefault(cfg, "AzureRegion", "eastus")
+	customEndpoint := config.GetStringOrDefault(cfg, "AzureEndpoint", "")
+
+	s := &synthesizer{
+		endpoint:    strings.TrimSuffix(customEndpoint, "/"),
+		voiceGender: config.GetStringOrDefault(cfg, "AzureVoiceGender", "Female"),
+		voiceName:   config.GetStringOrDefault(cfg, "AzureVoiceName", "en-US-JennyNeural"),
+		prosody: ssml.ProsodyOptions{
+			Rate:   config.GetStringOrDefault(cfg, "AzureVoiceRate", ""),
+			Pitch:  config.GetStringOrDefault(cfg, "AzureVoicePitch", ""),
+			Volume: config.GetStringOrDefault(cfg, "AzureVoiceVolume", ""),
+		},
+	}
+	if s.endpoint == "" {
+		s.endpoint = fmt.Sprintf(regionalEndpoint, region)
+	}
+
+	switch {
+	case subscriptionKey == "" && customEndpoint == "":
+		return nil, fmt.Errorf("AzureSubscriptionKey is not configured")
+	case subscriptionKey == "":
+		// Containers and other custom endpoints may not need a key
+		s.credentials = noCredentials{}
+	case config.GetBoolOrDefault(cfg, "AzureUseTokens", true):
+		// Resources with a custom domain issue tokens on the same host
+		defaultTokenURL := fmt.Sprintf(tokenEndpoint, region)
+		if customEndpoint != "" {
+			defaultTokenURL = s.endpoint + tokenPath
+		}
+		tokenURL := config.GetStringOrDefault(cfg, "AzureTokenURL", defaultTokenURL)
+		s.credentials = NewTokenIssuer(subscriptionKey, tokenURL)
+	default:
+		s.credentials = SubscriptionKey(subscriptionKey)
+	}
+	return s, nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	if opts.Voice != "" {
+		return nil
+	}
+	if opts.Gender != "" {
+		return fmt.Errorf("Azure selects gender by voice; specify a voice instead of a gender")
+	}
+	if opts.Language != "" && !strings.EqualFold(speech.LanguageFromVoiceName(s.voiceName), opts.Language) {
+		return fmt.Errorf("voice %s does not speak %s; specify a voice", s.voiceName, opts.Language)
+	}
+	return nil
+}
+
+func (s *synthesizer) MaxInputBytes() int {
+	return maxInputBytes
+}
+
+func (s *synthesizer) VoiceLanguage() string {
+	return speech.LanguageFromVoiceName(s.voiceName)
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	voice := ssml.VoiceOptions{
+		Name:   s.voiceName,
+		Lang:   opts.Language,
+		Gender: s.voiceGender,
+	}
+	if opts.Voice != "" {
+		voice.Name = opts.Voice
+	}
+	if opts.Gender != "" {
+		voice.Gender = strings.ToUpper(opts.Gender[:1]) + opts.Gender[1:]
+	}
+
+	prosody := s.prosody
+	if opts.Speed != 0 {
+		// Azure treats a bare number as a multiplier of the default rate
+		prosody.Rate = strconv.FormatFloat(opts.Speed, 'f', -1, 64)
+	}
+
+	audioData, err := SynthesizeSpeech(ctx, s.endpoint, s.credentials, generateSSML(text, voice, prosody))
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Data: audioData, Format: speech.FormatWAV}, nil
+}
+
+func (s *synthesizer) ListVoices() ([]speech.Voice, error) {
+	infos, err := ListVoices(s.endpoint, s.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]speech.Voice, 0, len(infos))
+	for _, info := range infos {
+		voices = append(voices, speech.Voice{
+			ID:       info.ShortName,
+			Name:     info.DisplayName,
+			Language: info.Locale,
+			Gender:   strings.ToLower(info.Gender),
+			Styles:   info.StyleList,
+		})
+	}
+	return voices, nil
+}
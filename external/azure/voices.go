@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	voicesPath = "/cognitiveservices/voices/list"
+)
+
+type VoiceInfo struct {
+	Name            string   `json:"Name"`
+	DisplayName     string   `json:"DisplayName"`
+	LocalName       string   `json:"LocalName"`
+	ShortName       string   `json:"ShortName"`
+	Gender          string   `json:"Gender"`
+	Locale          string   `json:"Locale"`
+	StyleList       []string `json:"StyleList"`
+	VoiceType       string   `json:"VoiceType"`
+	SampleRateHertz string   `json:"SampleRateHertz"`
+}
+
+// ListVoices returns the voices available at the Speech endpoint.
+func ListVoices(endpoint string, credentials Credentials) ([]VoiceInfo, error) {
+	resp, err := send(context.Background(), credentials, func() (*http.Request, error) {
+		return http.NewRequest("GET", endpoint+voicesPath, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, speech.ResponseError(resp)
+	}
+
+	var voices []VoiceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %v", err)
+	}
+	return voices, nil
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCommandLine = "espeak-ng --stdout -v {voice} -s {rate}"
+)
+
+// SynthesizeSpeech runs a local synthesizer command and returns the WAV data
+// it writes to stdout. The placeholders {voice}, {rate} and {text} are
+// substituted in each argument; when {text} is absent the text is written to
+// the command's stdin instead.
+func SynthesizeSpeech(ctx context.Context, commandLine, text, voice string, rate float64) ([]byte, error) {
+	args, err := splitCommandLine(commandLine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command line: %v", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("command line is empty")
+	}
+
+	replacer := strings.NewReplacer(
+		"{voice}", voice,
+		"{rate}", strconv.FormatFloat(rate, 'f', -1, 64),
+		"{text}", text,
+	)
+	textInArgs := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{text}") {
+			textInArgs = true
+		}
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if !textInArgs {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	audioData := stdout.Bytes()
+	if len(audioData) < 4 || string(audioData[:4]) != "RIFF" {
+		return nil, fmt.Errorf("command %s did not produce WAV output", args[0])
+	}
+
+	return audioData, nil
+}
+
+// splitCommandLine splits a command line into arguments, honouring single
+// and double quotes and backslash escapes. No shell is involved.
+func splitCommandLine(commandLine string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, char := range commandLine {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\'' || char == '"':
+			quote = char
+			inArg = true
+		case char == ' ' || char == '\t' || char == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(char)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+// echoArgs prints a WAV tag followed by the arguments it was given, and
+// echoStdin the tag followed by its standard input.
+const (
+	echoArgs  = `sh -c 'printf "RIFF%s|%s|%s" "$0" "$1" "$2"'`
+	echoStdin = `sh -c 'printf RIFF; cat'`
+)
+
+func TestSynthesizeSpeech(t *testing.T) {
+	tests := []struct {
+		name        string
+		commandLine string
+		text        string
+		want        string
+		wantErr     string
+	}{
+		{
+			name:        "placeholders",
+			commandLine: echoArgs + " {voice} {rate} {text}",
+			text:        "Hello there",
+			want:        "RIFFde|87.5|Hello there",
+		},
+		{
+			name:        "placeholders within an argument",
+			commandLine: echoArgs + " -v{voice} --rate={rate} '[{text}]'",
+			text:        "Hi",
+			want:        "RIFF-vde|--rate=87.5|[Hi]",
+		},
+		{
+			name:        "text is not run by a shell",
+			commandLine: echoArgs + " {voice} {rate} {text}",
+			text:        "$(echo pwned); `id`",
+			want:        "RIFFde|87.5|$(echo pwned); `id`",
+		},
+		{
+			name:        "text on stdin",
+			commandLine: echoStdin + " {voice}",
+			text:        "Hello there",
+			want:        "RIFFHello there",
+		},
+		{
+			name:        "not WAV",
+			commandLine: "echo {text}",
+			text:        "Hello",
+			wantErr:     "did not produce WAV output",
+		},
+		{
+			name:        "failure",
+			commandLine: `sh -c 'echo broken voice >&2; exit 1'`,
+			wantErr:     "broken voice",
+		},
+		{
+			name:        "empty",
+			commandLine: "  ",
+			wantErr:     "command line is empty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SynthesizeSpeech(context.Background(), test.commandLine, test.text, "de", 87.5)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("SynthesizeSpeech() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SynthesizeSpeech() error = %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("SynthesizeSpeech() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	provider, err := newSynthesizer(map[string]interface{}{
+		"CommandLine": echoArgs + " {voice} {rate} {text}",
+		"CommandRate": 200.0,
+	})
+	if err != nil {
+		t.Fatalf("newSynthesizer() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts speech.SynthesisOptions
+		want string
+	}{
+		{"defaults", speech.SynthesisOptions{}, "RIFFen|200|Hi"},
+		{"voice", speech.SynthesisOptions{Voice: "en-gb", Language: "fr"}, "RIFFen-gb|200|Hi"},
+		{"language", speech.SynthesisOptions{Language: "FR"}, "RIFFfr|200|Hi"},
+		{"speed", speech.SynthesisOptions{Speed: 1.5}, "RIFFen|300|Hi"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			audio, err := provider.Synthesize(context.Background(), "Hi", test.opts)
+			if err != nil {
+				t.Fatalf("Synthesize() error = %v", err)
+			}
+			if string(audio.Data) != test.want || audio.Format != speech.FormatWAV {
+				t.Errorf("Synthesize() = %q (%v), want %q (WAV)", audio.Data, audio.Format, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		commandLine string
+		want        []string
+		wantErr     bool
+	}{
+		{"espeak-ng --stdout -v {voice}", []string{"espeak-ng", "--stdout", "-v", "{voice}"}, false},
+		{"  a \t b\n", []string{"a", "b"}, false},
+		{`say "two words" 'it''s'`, []string{"say", "two words", "its"}, false},
+		{`a "say \"hi\"" 'no \escape'`, []string{"a", `say "hi"`, `no \escape`}, false},
+		{`two\ words ""`, []string{"two words", ""}, false},
+		{"", nil, false},
+		{`"unterminated`, nil, true},
+		{`trailing\`, nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := splitCommandLine(test.commandLine)
+		if (err != nil) != test.wantErr {
+			t.Errorf("splitCommandLine(%q) error = %v, want error %v", test.commandLine, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitCommandLine(%q) = %q, want %q", test.commandLine, got, test.want)
+		}
+	}
+}
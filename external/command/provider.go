@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+func init() {
+	speech.Register("Command", newSynthesizer)
+}
+
+type synthesizer struct {
+	commandLine string
+	voice       string
+	rate        float64
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	return &synthesizer{
+		commandLine: config.GetStringOrDefault(cfg, "CommandLine", defaultCommandLine),
+		voice:       config.GetStringOrDefault(cfg, "CommandVoice", "en"),
+		rate:        config.GetFloat64OrDefault(cfg, "CommandRate", 175),
+	}, nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	if opts.Gender != "" {
+		return fmt.Errorf("gender overrides are not supported; choose a voice instead")
+	}
+	return nil
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	voice := s.voice
+	if opts.Voice != "" {
+		voice = opts.Voice
+	} else if opts.Language != "" {
+		// espeak-ng names its voices after the language they speak
+		voice = strings.ToLower(opts.Language)
+	}
+
+	rate := s.rate
+	if opts.Speed != 0 {
+		rate *= opts.Speed
+	}
+
+	audioData, err := SynthesizeSpeech(ctx, s.commandLine, text, voice, rate)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Data: audioData, Format: speech.FormatWAV}, nil
+}
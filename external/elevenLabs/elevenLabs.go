@@ -2,14 +2,21 @@ package elevenLabs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
 )
 
 const (
 	apiEndpoint = "https://api.elevenlabs.io/v1/text-to-speech"
+
+	// maxInputBytes is the 5000 character limit of the multilingual models
+	maxInputBytes = 5000
 )
 
 type VoiceSettings struct {
@@ -36,8 +43,36 @@ type SynthesizeRequest struct {
 	NextRequestIDs                  []string                         `json:"next_request_ids,omitempty"`
 }
 
-func SynthesizeSpeech(subscriptionKey, voiceID, text string, voiceSettings VoiceSettings) ([]byte, error) {
+func SynthesizeSpeech(ctx context.Context, subscriptionKey, voiceID, text string, voiceSettings VoiceSettings) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s", apiEndpoint, voiceID)
+	resp, err := sendSynthesizeRequest(ctx, url, subscriptionKey, text, voiceSettings, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	audioData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	return audioData, nil
+}
 
+// SynthesizeSpeechStream uses the streaming endpoint and returns the MP3
+// response body as soon as the headers arrive, so playback can begin while
+// the rest of the audio is still being generated. The caller must close it;
+// canceling ctx after the headers have arrived doesn't end the stream.
+func SynthesizeSpeechStream(ctx context.Context, subscriptionKey, voiceID, text string, voiceSettings VoiceSettings) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/%s/stream", apiEndpoint, voiceID)
+	resp, err := sendSynthesizeRequest(ctx, url, subscriptionKey, text, voiceSettings, true)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func sendSynthesizeRequest(ctx context.Context, url, subscriptionKey, text string, voiceSettings VoiceSettings, stream bool) (*http.Response, error) {
 	requestBody := SynthesizeRequest{
 		Text:          text,
 		ModelID:       "eleven_monolingual_v1", // Ensure this is the correct model ID
@@ -49,14 +84,13 @@ func SynthesizeSpeech(subscriptionKey, voiceID, text string, voiceSettings Voice
 		return nil, fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	url := fmt.Sprintf("%s/%s", apiEndpoint, voiceID)
 	headers := map[string]string{
 		"xi-api-key":   subscriptionKey,
 		"Accept":       "audio/mpeg",
 		"Content-Type": "application/json",
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
@@ -65,23 +99,21 @@ func SynthesizeSpeech(subscriptionKey, voiceID, text string, voiceSettings Voice
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	var resp *http.Response
+	if stream {
+		resp, err = httpclient.DoStream(ctx, req)
+	} else {
+		resp, err = httpclient.Client().Do(req)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, speech.RequestError(err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// Read the response body for more details
-		errorBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status: %s, body: %s", resp.Status, string(errorBody))
+		err := speech.ResponseError(resp)
+		resp.Body.Close()
+		return nil, err
 	}
 
-	audioData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	return audioData, nil
+	return resp, nil
 }
@@ -0,0 +1,116 @@
+package elevenLabs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+func init() {
+	speech.Register("ElevenLabs", newSynthesizer)
+}
+
+type synthesizer struct {
+	subscriptionKey string
+	voiceID         string
+	voiceSettings   VoiceSettings
+}
+
+// streamingSynthesizer additionally implements speech.StreamSynthesizer so
+// audio is played while it is still being downloaded.
+type streamingSynthesizer struct {
+	*synthesizer
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	subscriptionKey, err := secret.Get(cfg, "ElevenLabsSubscriptionKey")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &synthesizer{
+		subscriptionKey: subscriptionKey,
+		voiceID:         config.GetStringOrDefault(cfg, "ElevenLabsVoiceModelID", "eleven_monolingual_v1"),
+		voiceSettings: VoiceSettings{
+			Stability:       config.GetFloat64OrDefault(cfg, "ElevenLabsVoiceStability", 0.5),
+			SimilarityBoost: config.GetFloat64OrDefault(cfg, "ElevenLabsVoiceSimilarityBoost", 0.5),
+			Style:           config.GetFloat64OrDefault(cfg, "ElevenLabsVoiceStyle", 0.5),
+			UseSpeakerBoost: config.GetBoolOrDefault(cfg, "ElevenLabsVoiceUseSpeakerBoost", false),
+		},
+	}
+	if s.subscriptionKey == "" {
+		return nil, fmt.Errorf("ElevenLabsSubscriptionKey is not configured")
+	}
+	if config.GetBoolOrDefault(cfg, "ElevenLabsStreaming", false) {
+		return streamingSynthesizer{s}, nil
+	}
+	return s, nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	switch {
+	case opts.Language != "":
+		return fmt.Errorf("language overrides are not supported; choose a voice instead")
+	case opts.Gender != "":
+		return fmt.Errorf("gender overrides are not supported; choose a voice instead")
+	case opts.Speed != 0:
+		return fmt.Errorf("speed overrides are not supported")
+	}
+	return nil
+}
+
+func (s *synthesizer) MaxInputBytes() int {
+	return maxInputBytes
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	audioData, err := SynthesizeSpeech(ctx, s.subscriptionKey, s.voice(opts), text, s.voiceSettings)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Data: audioData, Format: speech.FormatMP3}, nil
+}
+
+func (s streamingSynthesizer) SynthesizeStream(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	audioStream, err := SynthesizeSpeechStream(ctx, s.subscriptionKey, s.voice(opts), text, s.voiceSettings)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Stream: audioStream, Format: speech.FormatMP3}, nil
+}
+
+func (s *synthesizer) voice(opts speech.SynthesisOptions) string {
+	if opts.Voice != "" {
+		return opts.Voice
+	}
+	return s.voiceID
+}
+
+func (s *synthesizer) ListVoices() ([]speech.Voice, error) {
+	infos, err := ListVoices(s.subscriptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]speech.Voice, 0, len(infos))
+	for _, info := range infos {
+		var styles []string
+		for _, label := range []string{"description", "use_case", "accent", "age"} {
+			if value := info.Labels[label]; value != "" {
+				styles = append(styles, value)
+			}
+		}
+		voices = append(voices, speech.Voice{
+			ID:       info.VoiceID,
+			Name:     info.Name,
+			Language: info.Labels["language"],
+			Gender:   strings.ToLower(info.Labels["gender"]),
+			Styles:   styles,
+		})
+	}
+	return voices, nil
+}
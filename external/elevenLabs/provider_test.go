@@ -0,0 +1,30 @@
+package elevenLabs
+
+import (
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    speech.SynthesisOptions
+		wantErr bool
+	}{
+		{"no overrides", speech.SynthesisOptions{}, false},
+		{"voice", speech.SynthesisOptions{Voice: "21m00Tcm4TlvDq8ikWAM"}, false},
+		{"speed", speech.SynthesisOptions{Speed: 1.5}, true},
+		{"language", speech.SynthesisOptions{Language: "de"}, true},
+		{"gender", speech.SynthesisOptions{Gender: "male"}, true},
+	}
+
+	s := &synthesizer{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := s.ValidateOptions(test.opts); (err != nil) != test.wantErr {
+				t.Errorf("ValidateOptions(%+v) error = %v, want error %v", test.opts, err, test.wantErr)
+			}
+		})
+	}
+}
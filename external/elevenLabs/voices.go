@@ -0,0 +1,51 @@
+package elevenLabs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	voicesEndpoint = "https://api.elevenlabs.io/v1/voices"
+)
+
+type VoiceInfo struct {
+	VoiceID  string            `json:"voice_id"`
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type listVoicesResponse struct {
+	Voices []VoiceInfo `json:"voices"`
+}
+
+// ListVoices returns the voices available to the account.
+func ListVoices(subscriptionKey string) ([]VoiceInfo, error) {
+	req, err := http.NewRequest("GET", voicesEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("xi-api-key", subscriptionKey)
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, speech.ResponseError(resp)
+	}
+
+	var listResponse listVoicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %v", err)
+	}
+	return listResponse.Voices, nil
+}
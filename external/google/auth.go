@@ -0,0 +1,262 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+	cloudScope      = "https://www.googleapis.com/auth/cloud-platform"
+
+	// tokenRefreshMargin renews access tokens this long before they expire
+	tokenRefreshMargin = 5 * time.Minute
+	// minTokenLifetime is assumed for tokens said to expire sooner, such as
+	// one without expires_in, so they aren't fetched again for every request
+	minTokenLifetime = tokenRefreshMargin + time.Minute
+)
+
+// Credentials authorize requests to the Text-to-Speech API.
+type Credentials interface {
+	Authorize(ctx context.Context, req *http.Request) error
+}
+
+// APIKey authorizes requests with an API key.
+type APIKey string
+
+func (k APIKey) Authorize(ctx context.Context, req *http.Request) error {
+	// The key goes in a header so it never shows up in logged URLs
+	req.Header.Set("X-Goog-Api-Key", string(k))
+	return nil
+}
+
+// ServiceAccount authorizes requests with OAuth access tokens obtained by
+// signing a JWT assertion with the service account's private key. Tokens
+// are cached and renewed shortly before they expire.
+type ServiceAccount struct {
+	email      string
+	keyID      string
+	privateKey *rsa.PrivateKey
+	tokenURL   string
+
+	mutex       sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+type serviceAccountFile struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+var (
+	serviceAccountsMutex sync.Mutex
+	serviceAccounts      = make(map[string]*ServiceAccount)
+)
+
+// LoadServiceAccount reads a service account key file as downloaded from the
+// Google Cloud console. A non-empty tokenURL overrides the token endpoint
+// named in the file. Accounts are shared per file, key and endpoint, so
+// their tokens survive between requests but not a key rotated in place.
+func LoadServiceAccount(path, tokenURL string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account file: %v", err)
+	}
+	var file serviceAccountFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse service account file: %v", err)
+	}
+	if file.Type != "service_account" || file.ClientEmail == "" {
+		return nil, fmt.Errorf("%s is not a service account key file", path)
+	}
+
+	serviceAccountsMutex.Lock()
+	defer serviceAccountsMutex.Unlock()
+
+	cacheKey := strings.Join([]string{path, file.ClientEmail, file.PrivateKeyID, tokenURL}, "\x00")
+	if account, ok := serviceAccounts[cacheKey]; ok {
+		return account, nil
+	}
+
+	privateKey, err := parsePrivateKey(file.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenURL == "" {
+		tokenURL = file.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	account := &ServiceAccount{
+		email:      file.ClientEmail,
+		keyID:      file.PrivateKeyID,
+		privateKey: privateKey,
+		tokenURL:   tokenURL,
+	}
+	serviceAccounts[cacheKey] = account
+	return account, nil
+}
+
+func (a *ServiceAccount) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := a.token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// token returns a valid access token, fetching a new one if the cached
+// token is missing or about to expire.
+func (a *ServiceAccount) token(ctx context.Context) (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	if a.accessToken != "" && now.Add(tokenRefreshMargin).Before(a.expiry) {
+		return a.accessToken, nil
+	}
+
+	assertion, err := a.signAssertion(now)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := speech.ResponseError(resp)
+		var providerErr *speech.ProviderError
+		if errors.As(err, &providerErr) && providerErr.Kind == speech.ErrorRejected {
+			// The token endpoint answers 400 for revoked or unknown keys
+			providerErr.Kind = speech.ErrorAuth
+		}
+		return "", err
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %v", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("token response contains no access token")
+	}
+
+	secret.Register(tokenResp.AccessToken)
+	lifetime := time.Duration(tokenResp.ExpiresIn) * time.Second
+	if lifetime < minTokenLifetime {
+		lifetime = minTokenLifetime
+	}
+	a.accessToken = tokenResp.AccessToken
+	a.expiry = now.Add(lifetime)
+	return a.accessToken, nil
+}
+
+// signAssertion builds the RS256-signed JWT exchanged for an access token.
+func (a *ServiceAccount) signAssertion(now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if a.keyID != "" {
+		header["kid"] = a.keyID
+	}
+	claims := map[string]interface{}{
+		"iss":   a.email,
+		"scope": cloudScope,
+		"aud":   a.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodedHeader + "." + encodedClaims
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token assertion: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeSegment(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token assertion: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// parsePrivateKey decodes a PEM encoded RSA key in PKCS #8 form, as Google
+// issues them, or in PKCS #1 form.
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("service account private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account private key: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,233 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+// writeKeyFile writes a service account key file for key to path.
+func writeKeyFile(t *testing.T, path string, key *rsa.PrivateKey, keyID string) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(serviceAccountFile{
+		Type:         "service_account",
+		ClientEmail:  "tts@example.iam.gserviceaccount.com",
+		PrivateKeyID: keyID,
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:     "https://oauth2.example.com/token",
+	})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tokenServer issues tokens for assertions signed by key, numbering them so
+// a test can tell whether a token was reused.
+type tokenServer struct {
+	*httptest.Server
+	requests  atomic.Int32
+	expiresIn int
+}
+
+func newTokenServer(t *testing.T, key *rsa.PrivateKey, keyID string) *tokenServer {
+	ts := &tokenServer{expiresIn: 3600}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := ts.requests.Add(1)
+		if err := checkAssertion(r, &key.PublicKey, keyID, ts.URL); err != nil {
+			t.Errorf("token request: %v", err)
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: fmt.Sprintf("token-%d", n), ExpiresIn: ts.expiresIn, TokenType: "Bearer"})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// checkAssertion verifies the JWT bearer grant of a token request.
+func checkAssertion(r *http.Request, key *rsa.PublicKey, keyID, audience string) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	if grant := r.PostForm.Get("grant_type"); grant != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		return fmt.Errorf("grant_type = %q", grant)
+	}
+
+	parts := strings.Split(r.PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("assertion has %d parts", len(parts))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("bad signature: %v", err)
+	}
+
+	var header map[string]string
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return err
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return err
+	}
+	if header["alg"] != "RS256" || header["kid"] != keyID {
+		return fmt.Errorf("header = %v", header)
+	}
+	now := float64(time.Now().Unix())
+	iat, _ := claims["iat"].(float64)
+	exp, _ := claims["exp"].(float64)
+	if claims["iss"] != "tts@example.iam.gserviceaccount.com" || claims["aud"] != audience || claims["scope"] != cloudScope || iat > now+1 || exp <= now {
+		return fmt.Errorf("claims = %v", claims)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func authorization(t *testing.T, account *ServiceAccount) string {
+	t.Helper()
+	req, _ := http.NewRequest("POST", "https://texttospeech.googleapis.com/v1/text:synthesize", nil)
+	if err := account.Authorize(context.Background(), req); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	return req.Header.Get("Authorization")
+}
+
+func TestServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTokenServer(t, key, "key-1")
+	path := filepath.Join(t.TempDir(), "account.json")
+	writeKeyFile(t, path, key, "key-1")
+
+	account, err := LoadServiceAccount(path, server.URL)
+	if err != nil {
+		t.Fatalf("LoadServiceAccount() error = %v", err)
+	}
+	if got := authorization(t, account); got != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want Bearer token-1", got)
+	}
+
+	// The token is reused, also by accounts loaded from the same file
+	again, err := LoadServiceAccount(path, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := authorization(t, again); got != "Bearer token-1" {
+		t.Errorf("Authorization = %q, want the reused token-1", got)
+	}
+
+	// and renewed shortly before it expires
+	account.mutex.Lock()
+	account.expiry = time.Now().Add(tokenRefreshMargin - time.Second)
+	account.mutex.Unlock()
+	if got := authorization(t, account); got != "Bearer token-2" {
+		t.Errorf("Authorization = %q, want a new token-2", got)
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("token endpoint called %d times, want 2", got)
+	}
+}
+
+func TestServiceAccountShortLivedToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTokenServer(t, key, "")
+	server.expiresIn = 0
+	path := filepath.Join(t.TempDir(), "account.json")
+	writeKeyFile(t, path, key, "")
+
+	account, err := LoadServiceAccount(path, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization(t, account)
+	authorization(t, account)
+	if got := server.requests.Load(); got != 1 {
+		t.Errorf("token endpoint called %d times, want 1", got)
+	}
+}
+
+func TestServiceAccountRotatedKey(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "account.json")
+	writeKeyFile(t, path, oldKey, "old")
+	old, err := LoadServiceAccount(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeKeyFile(t, path, newKey, "new")
+	rotated, err := LoadServiceAccount(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == old || rotated.keyID != "new" || !rotated.privateKey.Equal(newKey) {
+		t.Error("LoadServiceAccount() kept the replaced key")
+	}
+}
+
+func TestServiceAccountRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "account.json")
+	writeKeyFile(t, path, key, "revoked")
+
+	account, err := LoadServiceAccount(path, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("POST", server.URL, nil)
+	err = account.Authorize(context.Background(), req)
+	if speech.ErrorCategory(err) != string(speech.ErrorAuth) {
+		t.Errorf("Authorize() error = %v, want auth", err)
+	}
+}
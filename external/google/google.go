@@ -2,73 +2,65 @@ package google
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+	"github.com/ln64-git/voxctl/internal/ssml"
 )
 
 const (
 	apiEndpoint = "https://texttospeech.googleapis.com/v1/text:synthesize"
+
+	// maxInputBytes keeps the SSML document under the 5000 byte input limit
+	maxInputBytes = 4500
 )
 
+type SynthesisInput struct {
+	Text string `json:"text,omitempty"`
+	SSML string `json:"ssml,omitempty"`
+}
+
+type VoiceSelectionParams struct {
+	LanguageCode string `json:"languageCode"`
+	Name         string `json:"name,omitempty"`
+	SsmlGender   string `json:"ssmlGender,omitempty"`
+}
+
+type AudioConfig struct {
+	AudioEncoding string  `json:"audioEncoding"`
+	SpeakingRate  float64 `json:"speakingRate,omitempty"`
+}
+
 type SynthesizeRequest struct {
-	Input struct {
-		Text string `json:"text"`
-	} `json:"input"`
-	Voice struct {
-		LanguageCode string `json:"languageCode"`
-		Name         string `json:"name,omitempty"`
-	} `json:"voice"`
-	AudioConfig struct {
-		AudioEncoding string `json:"audioEncoding"`
-	} `json:"audioConfig"`
+	Input       SynthesisInput       `json:"input"`
+	Voice       VoiceSelectionParams `json:"voice"`
+	AudioConfig AudioConfig          `json:"audioConfig"`
 }
 
 type SynthesizeResponse struct {
 	AudioContent string `json:"audioContent"`
 }
 
-func SynthesizeSpeech(apiKey, text, languageCode, voiceName string) ([]byte, error) {
-	log.Infof("apiKey: %s", apiKey)
-	log.Infof("text: %s", text)
-	log.Infof("languageCode: %s", languageCode)
-	log.Infof("voiceName: %s", voiceName)
-
-	requestBody := SynthesizeRequest{
-		Input: struct {
-			Text string `json:"text"`
-		}{
-			Text: text,
-		},
-		Voice: struct {
-			LanguageCode string `json:"languageCode"`
-			Name         string `json:"name,omitempty"`
-		}{
-			LanguageCode: languageCode,
-			Name:         voiceName,
-		},
-		AudioConfig: struct {
-			AudioEncoding string `json:"audioEncoding"`
-		}{
-			AudioEncoding: "MP3",
-		},
-	}
+func SynthesizeSpeech(ctx context.Context, credentials Credentials, requestBody SynthesizeRequest) ([]byte, error) {
+	log.Infof("languageCode: %s", requestBody.Voice.LanguageCode)
+	log.Infof("voiceName: %s", requestBody.Voice.Name)
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	url := fmt.Sprintf("%s?key=%s", apiEndpoint, apiKey)
 	headers := map[string]string{
 		"Content-Type": "application/json",
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiEndpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
@@ -76,17 +68,19 @@ func SynthesizeSpeech(apiKey, text, languageCode, voiceName string) ([]byte, err
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	if err := credentials.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
 
-	client := &http.Client{}
+	client := httpclient.Client()
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return nil, speech.RequestError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		errorBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status: %s, body: %s", resp.Status, string(errorBody))
+		return nil, speech.ResponseError(resp)
 	}
 
 	// Read and decode the response
@@ -102,3 +96,12 @@ func SynthesizeSpeech(apiKey, text, languageCode, voiceName string) ([]byte, err
 
 	return audioData, nil
 }
+
+// generateSSML wraps text in a speak document, applying prosody if set.
+func generateSSML(text string, prosody ssml.ProsodyOptions) string {
+	content := ssml.Text(text)
+	if !prosody.IsZero() {
+		content = ssml.Prosody(prosody, content)
+	}
+	return ssml.Speak("", content).String()
+}
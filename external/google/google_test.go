@@ -0,0 +1,36 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+func TestGenerateSSML(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		prosody ssml.ProsodyOptions
+		want    string
+	}{
+		{
+			name: "escapes text",
+			text: "R&D <draft> 'v2'",
+			want: `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis">R&amp;D &lt;draft&gt; &#39;v2&#39;</speak>`,
+		},
+		{
+			name:    "prosody",
+			text:    "Slowly.",
+			prosody: ssml.ProsodyOptions{Rate: "slow", Pitch: "-2st"},
+			want:    `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis"><prosody rate="slow" pitch="-2st">Slowly.</prosody></speak>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := generateSSML(test.text, test.prosody); got != test.want {
+				t.Errorf("generateSSML() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
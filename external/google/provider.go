@@ -0,0 +1,131 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+func init() {
+	speech.Register("Google", newSynthesizer)
+}
+
+type synthesizer struct {
+	credentials  Credentials
+	languageCode string
+	voiceName    string
+	prosody      ssml.ProsodyOptions
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	credentials, err := loadCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &synthesizer{
+		credentials:  credentials,
+		languageCode: config.GetStringOrDefault(cfg, "GoogleLanguageCode", "en-US"),
+		voiceName:    config.GetStringOrDefault(cfg, "GoogleVoiceName", "en-US-Wavenet-D"),
+		prosody: ssml.ProsodyOptions{
+			Rate:   config.GetStringOrDefault(cfg, "GoogleVoiceRate", ""),
+			Pitch:  config.GetStringOrDefault(cfg, "GoogleVoicePitch", ""),
+			Volume: config.GetStringOrDefault(cfg, "GoogleVoiceVolume", ""),
+		},
+	}
+	return s, nil
+}
+
+// loadCredentials prefers a service account, configured through
+// GoogleServiceAccountFile and optionally GoogleTokenURL, over an API key.
+func loadCredentials(cfg map[string]interface{}) (Credentials, error) {
+	if path := config.GetStringOrDefault(cfg, "GoogleServiceAccountFile", ""); path != "" {
+		return LoadServiceAccount(path, config.GetStringOrDefault(cfg, "GoogleTokenURL", ""))
+	}
+
+	apiKey, err := secret.Get(cfg, "GoogleSubscriptionKey")
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("GoogleSubscriptionKey or GoogleServiceAccountFile must be configured")
+	}
+	return APIKey(apiKey), nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	return nil
+}
+
+func (s *synthesizer) MaxInputBytes() int {
+	return maxInputBytes
+}
+
+func (s *synthesizer) VoiceLanguage() string {
+	if code := speech.LanguageFromVoiceName(s.voiceName); code != "" {
+		return code
+	}
+	return s.languageCode
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	voice := VoiceSelectionParams{
+		LanguageCode: s.languageCode,
+		Name:         s.voiceName,
+		SsmlGender:   strings.ToUpper(opts.Gender),
+	}
+	if opts.Voice != "" {
+		voice.Name = opts.Voice
+		if code := speech.LanguageFromVoiceName(voice.Name); code != "" {
+			voice.LanguageCode = code
+		}
+	} else if opts.Gender != "" || (opts.Language != "" && !strings.EqualFold(opts.Language, voice.LanguageCode)) {
+		// Let Google pick a voice matching the requested language and gender
+		voice.Name = ""
+	}
+	if opts.Language != "" {
+		voice.LanguageCode = opts.Language
+	}
+
+	requestBody := SynthesizeRequest{
+		Input: SynthesisInput{
+			SSML: generateSSML(text, s.prosody),
+		},
+		Voice: voice,
+		AudioConfig: AudioConfig{
+			AudioEncoding: "MP3",
+			SpeakingRate:  opts.Speed,
+		},
+	}
+	audioData, err := SynthesizeSpeech(ctx, s.credentials, requestBody)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Data: audioData, Format: speech.FormatMP3}, nil
+}
+
+func (s *synthesizer) ListVoices() ([]speech.Voice, error) {
+	infos, err := ListVoices(s.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var voices []speech.Voice
+	for _, info := range infos {
+		// Multilingual voices are listed once per language they speak
+		for _, languageCode := range info.LanguageCodes {
+			voices = append(voices, speech.Voice{
+				ID:       info.Name,
+				Name:     info.Name,
+				Language: languageCode,
+				Gender:   strings.ToLower(info.SsmlGender),
+			})
+		}
+	}
+	return voices, nil
+}
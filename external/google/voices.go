@@ -0,0 +1,53 @@
+package google
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	voicesEndpoint = "https://texttospeech.googleapis.com/v1/voices"
+)
+
+type VoiceInfo struct {
+	LanguageCodes          []string `json:"languageCodes"`
+	Name                   string   `json:"name"`
+	SsmlGender             string   `json:"ssmlGender"`
+	NaturalSampleRateHertz int      `json:"naturalSampleRateHertz"`
+}
+
+type listVoicesResponse struct {
+	Voices []VoiceInfo `json:"voices"`
+}
+
+// ListVoices returns every voice supported by the Text-to-Speech API.
+func ListVoices(credentials Credentials) ([]VoiceInfo, error) {
+	req, err := http.NewRequest("GET", voicesEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if err := credentials.Authorize(req.Context(), req); err != nil {
+		return nil, err
+	}
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, speech.ResponseError(resp)
+	}
+
+	var listResponse listVoicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response body: %v", err)
+	}
+	return listResponse.Voices, nil
+}
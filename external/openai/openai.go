@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	defaultBaseURL = "https://api.openai.com/v1"
+	speechPath     = "/audio/speech"
+
+	// maxInputBytes is the 4096 character input limit of the speech endpoint
+	maxInputBytes = 4096
+)
+
+type SynthesizeRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	Speed          float64 `json:"speed,omitempty"`
+	ResponseFormat string  `json:"response_format,omitempty"`
+}
+
+// SynthesizeSpeech posts the request to an OpenAI-compatible /v1/audio/speech
+// endpoint under baseURL. The API key is optional for self-hosted servers.
+func SynthesizeSpeech(ctx context.Context, baseURL, apiKey string, requestBody SynthesizeRequest) ([]byte, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", speechURL(baseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, speech.ResponseError(resp)
+	}
+
+	audioData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	return audioData, nil
+}
+
+// speechURL builds the speech endpoint from a base URL given either with or
+// without the trailing /v1 segment.
+func speechURL(baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+	return baseURL + speechPath
+}
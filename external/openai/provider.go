@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+func init() {
+	speech.Register("OpenAI", newSynthesizer)
+}
+
+type synthesizer struct {
+	baseURL        string
+	apiKey         string
+	model          string
+	voice          string
+	speed          float64
+	responseFormat string
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	apiKey, err := secret.Get(cfg, "OpenAISubscriptionKey")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &synthesizer{
+		baseURL:        config.GetStringOrDefault(cfg, "OpenAIBaseURL", defaultBaseURL),
+		apiKey:         apiKey,
+		model:          config.GetStringOrDefault(cfg, "OpenAIModel", "tts-1"),
+		voice:          config.GetStringOrDefault(cfg, "OpenAIVoice", "alloy"),
+		speed:          config.GetFloat64OrDefault(cfg, "OpenAISpeed", 1.0),
+		responseFormat: config.GetStringOrDefault(cfg, "OpenAIResponseFormat", "mp3"),
+	}
+	if s.baseURL == defaultBaseURL && s.apiKey == "" {
+		return nil, fmt.Errorf("OpenAISubscriptionKey is not configured")
+	}
+	if s.speed < 0.25 || s.speed > 4.0 {
+		return nil, fmt.Errorf("OpenAISpeed must be between 0.25 and 4.0, got %v", s.speed)
+	}
+	if s.responseFormat != "mp3" && s.responseFormat != "wav" {
+		return nil, fmt.Errorf("OpenAIResponseFormat %q is not supported for playback (use mp3 or wav)", s.responseFormat)
+	}
+	return s, nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	switch {
+	case opts.Language != "":
+		return fmt.Errorf("language overrides are not supported; the model detects the language from the text")
+	case opts.Gender != "":
+		return fmt.Errorf("gender overrides are not supported; choose a voice instead")
+	}
+	return nil
+}
+
+func (s *synthesizer) MaxInputBytes() int {
+	return maxInputBytes
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	voice := s.voice
+	if opts.Voice != "" {
+		voice = opts.Voice
+	}
+	speed := s.speed
+	if opts.Speed != 0 {
+		speed = opts.Speed
+	}
+	requestBody := SynthesizeRequest{
+		Model:          s.model,
+		Input:          text,
+		Voice:          voice,
+		Speed:          speed,
+		ResponseFormat: s.responseFormat,
+	}
+	audioData, err := SynthesizeSpeech(ctx, s.baseURL, s.apiKey, requestBody)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+	return speech.Audio{Data: audioData, Format: speech.AudioFormat(s.responseFormat)}, nil
+}
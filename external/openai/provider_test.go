@@ -0,0 +1,25 @@
+package openai
+
+import (
+	"testing"
+)
+
+func TestNewSynthesizerSpeed(t *testing.T) {
+	tests := []struct {
+		speed   float64
+		wantErr bool
+	}{
+		{0.25, false},
+		{1.0, false},
+		{4.0, false},
+		{0.2, true},
+		{4.5, true},
+	}
+
+	for _, test := range tests {
+		_, err := newSynthesizer(map[string]interface{}{"OpenAISubscriptionKey": "key", "OpenAISpeed": test.speed})
+		if (err != nil) != test.wantErr {
+			t.Errorf("newSynthesizer(OpenAISpeed %v) error = %v, want error %v", test.speed, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package polly
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/httpclient"
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+const (
+	apiEndpoint = "https://polly.%s.amazonaws.com"
+	speechPath  = "/v1/speech"
+	serviceName = "polly"
+
+	// maxInputBytes is the 3000 billed character limit of SynthesizeSpeech
+	maxInputBytes = 3000
+
+	// maxSpeed is the fastest prosody rate Polly accepts, 200%
+	maxSpeed = 2.0
+)
+
+type SynthesizeRequest struct {
+	Engine       string `json:"Engine,omitempty"`
+	LanguageCode string `json:"LanguageCode,omitempty"`
+	OutputFormat string `json:"OutputFormat"`
+	SampleRate   string `json:"SampleRate,omitempty"`
+	Text         string `json:"Text"`
+	TextType     string `json:"TextType,omitempty"`
+	VoiceId      string `json:"VoiceId"`
+}
+
+// SynthesizeSpeech calls the Polly SynthesizeSpeech REST API and returns the
+// audio in the requested output format. An empty endpoint selects the public
+// regional endpoint.
+func SynthesizeSpeech(ctx context.Context, endpoint, region string, creds Credentials, requestBody SynthesizeRequest) ([]byte, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf(apiEndpoint, region)
+	}
+	url := strings.TrimRight(endpoint, "/") + speechPath
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signRequest(req, jsonData, creds, region, serviceName, time.Now())
+
+	client := httpclient.Client()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, speech.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, speech.ResponseError(resp)
+	}
+
+	audioData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	return audioData, nil
+}
+
+// pcmToWAV wraps the raw 16-bit signed little-endian mono PCM that Polly
+// returns in a WAV header so the audio player can decode it.
+func pcmToWAV(pcm []byte, sampleRate string) ([]byte, error) {
+	rate, err := strconv.Atoi(sampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample rate %q: %v", sampleRate, err)
+	}
+
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	blockAlign := channels * bitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(pcm)))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(rate))
+	binary.Write(&buf, binary.LittleEndian, uint32(rate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+
+	return buf.Bytes(), nil
+}
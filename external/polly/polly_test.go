@@ -0,0 +1,172 @@
+package polly
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+// pollyServer stands in for Polly. It checks the request signature by
+// signing the same request again and answers with audio.
+func pollyServer(t *testing.T, creds Credentials, region string, audio []byte, got *SynthesizeRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != speechPath {
+			t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, speechPath)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", contentType)
+		}
+
+		now, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+		if err != nil {
+			t.Errorf("X-Amz-Date: %v", err)
+		}
+		want, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.Path, nil)
+		want.Header.Set("Content-Type", "application/json")
+		signRequest(want, body, creds, region, serviceName, now)
+		if got, want := r.Header.Get("Authorization"), want.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		if token := r.Header.Get("X-Amz-Security-Token"); token != creds.SessionToken {
+			t.Errorf("X-Amz-Security-Token = %q, want %q", token, creds.SessionToken)
+		}
+
+		if err := json.Unmarshal(body, got); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		w.Write(audio)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSynthesize(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+	pcm := []byte{1, 0, 2, 0, 3, 0}
+	var got SynthesizeRequest
+	server := pollyServer(t, creds, "eu-west-1", pcm, &got)
+
+	provider, err := newSynthesizer(map[string]interface{}{
+		"PollyAccessKeyID":     creds.AccessKeyID,
+		"PollySecretAccessKey": creds.SecretAccessKey,
+		"PollySessionToken":    creds.SessionToken,
+		"PollyEndpoint":        server.URL,
+		"PollyRegion":          "eu-west-1",
+		"PollyOutputFormat":    "pcm",
+	})
+	if err != nil {
+		t.Fatalf("newSynthesizer() error = %v", err)
+	}
+
+	audio, err := provider.Synthesize(context.Background(), "Hello", speech.SynthesisOptions{Voice: "Vicki"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	want := SynthesizeRequest{
+		Engine:       "neural",
+		OutputFormat: "pcm",
+		SampleRate:   "16000",
+		Text:         "Hello",
+		TextType:     "text",
+		VoiceId:      "Vicki",
+	}
+	if got != want {
+		t.Errorf("request = %+v, want %+v", got, want)
+	}
+
+	if audio.Format != speech.FormatWAV {
+		t.Errorf("Format = %v, want WAV", audio.Format)
+	}
+	wav, _ := pcmToWAV(pcm, "16000")
+	if !bytes.Equal(audio.Data, wav) {
+		t.Errorf("Data = %v, want %v", audio.Data, wav)
+	}
+}
+
+func TestSynthesizeMarkup(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+	var got SynthesizeRequest
+	server := pollyServer(t, creds, "us-east-1", []byte("mp3"), &got)
+
+	provider, err := newSynthesizer(map[string]interface{}{
+		"PollyAccessKeyID":     creds.AccessKeyID,
+		"PollySecretAccessKey": creds.SecretAccessKey,
+		"PollyEndpoint":        server.URL,
+		"PollyTextType":        "ssml",
+	})
+	if err != nil {
+		t.Fatalf("newSynthesizer() error = %v", err)
+	}
+
+	audio, err := provider.Synthesize(context.Background(), `Wait <break time="1s"/> now.`, speech.SynthesisOptions{})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if want := `<speak>Wait <break time="1s"/> now.</speak>`; got.Text != want || got.TextType != "ssml" {
+		t.Errorf("request text = %q (%s), want %q (ssml)", got.Text, got.TextType, want)
+	}
+	if audio.Format != speech.FormatMP3 || string(audio.Data) != "mp3" {
+		t.Errorf("audio = %v %q, want the MP3 as it is", audio.Format, audio.Data)
+	}
+}
+
+func TestPCMToWAV(t *testing.T) {
+	wav, err := pcmToWAV([]byte{1, 2, 3, 4}, "22050")
+	if err != nil {
+		t.Fatalf("pcmToWAV() error = %v", err)
+	}
+	if len(wav) != 44+4 || string(wav[:4]) != "RIFF" || string(wav[8:12]) != "WAVE" || string(wav[36:40]) != "data" {
+		t.Fatalf("pcmToWAV() header = %q", wav[:44])
+	}
+	le := func(b []byte) int { return int(binary.LittleEndian.Uint32(b)) }
+	if size := le(wav[4:8]); size != 36+4 {
+		t.Errorf("RIFF size = %d, want %d", size, 36+4)
+	}
+	if rate := le(wav[24:28]); rate != 22050 {
+		t.Errorf("sample rate = %d, want 22050", rate)
+	}
+	if byteRate := le(wav[28:32]); byteRate != 44100 {
+		t.Errorf("byte rate = %d, want 44100", byteRate)
+	}
+	if size := le(wav[40:44]); size != 4 {
+		t.Errorf("data size = %d, want 4", size)
+	}
+
+	if _, err := pcmToWAV(nil, "fast"); err == nil {
+		t.Error("pcmToWAV() with a bad sample rate succeeded")
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		textType string
+		opts     speech.SynthesisOptions
+		wantErr  bool
+	}{
+		{"no overrides", "text", speech.SynthesisOptions{}, false},
+		{"slow", "text", speech.SynthesisOptions{Speed: 0.25}, false},
+		{"fastest", "text", speech.SynthesisOptions{Speed: maxSpeed}, false},
+		{"too fast", "text", speech.SynthesisOptions{Speed: 2.5}, true},
+		{"speed with markup", "ssml", speech.SynthesisOptions{Speed: 1.5}, true},
+		{"gender", "text", speech.SynthesisOptions{Gender: "female"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &synthesizer{textType: test.textType}
+			if err := s.ValidateOptions(test.opts); (err != nil) != test.wantErr {
+				t.Errorf("ValidateOptions(%+v) error = %v, want error %v", test.opts, err, test.wantErr)
+			}
+		})
+	}
+}
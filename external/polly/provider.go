@@ -0,0 +1,134 @@
+package polly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+	"github.com/ln64-git/voxctl/internal/speech"
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+func init() {
+	speech.Register("Polly", newSynthesizer)
+}
+
+type synthesizer struct {
+	endpoint     string
+	region       string
+	credentials  Credentials
+	engine       string
+	voiceID      string
+	outputFormat string
+	sampleRate   string
+	textType     string
+}
+
+func newSynthesizer(cfg map[string]interface{}) (speech.Synthesizer, error) {
+	var credentials Credentials
+	for key, value := range map[string]*string{
+		"PollyAccessKeyID":     &credentials.AccessKeyID,
+		"PollySecretAccessKey": &credentials.SecretAccessKey,
+		"PollySessionToken":    &credentials.SessionToken,
+	} {
+		resolved, err := secret.Get(cfg, key)
+		if err != nil {
+			return nil, err
+		}
+		*value = resolved
+	}
+
+	s := &synthesizer{
+		endpoint:     config.GetStringOrDefault(cfg, "PollyEndpoint", ""),
+		region:       config.GetStringOrDefault(cfg, "PollyRegion", "us-east-1"),
+		credentials:  credentials,
+		engine:       config.GetStringOrDefault(cfg, "PollyEngine", "neural"),
+		voiceID:      config.GetStringOrDefault(cfg, "PollyVoiceID", "Joanna"),
+		outputFormat: config.GetStringOrDefault(cfg, "PollyOutputFormat", "mp3"),
+		sampleRate:   config.GetStringOrDefault(cfg, "PollySampleRate", ""),
+		textType:     config.GetStringOrDefault(cfg, "PollyTextType", "text"),
+	}
+	if s.credentials.AccessKeyID == "" || s.credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("PollyAccessKeyID and PollySecretAccessKey must be configured")
+	}
+	switch s.engine {
+	case "standard", "neural", "long-form", "generative":
+	default:
+		return nil, fmt.Errorf("PollyEngine %q is not supported", s.engine)
+	}
+	switch s.outputFormat {
+	case "mp3":
+	case "pcm":
+		if s.sampleRate == "" {
+			s.sampleRate = "16000"
+		}
+	default:
+		return nil, fmt.Errorf("PollyOutputFormat %q is not supported for playback (use mp3 or pcm)", s.outputFormat)
+	}
+	if s.textType != "text" && s.textType != "ssml" {
+		return nil, fmt.Errorf("PollyTextType must be text or ssml, got %q", s.textType)
+	}
+	return s, nil
+}
+
+func (s *synthesizer) ValidateOptions(opts speech.SynthesisOptions) error {
+	switch {
+	case opts.Gender != "":
+		return fmt.Errorf("gender overrides are not supported; choose a voice instead")
+	case opts.Speed != 0 && s.textType != "text":
+		return fmt.Errorf("speed overrides are only supported with PollyTextType text")
+	case opts.Speed > maxSpeed:
+		return fmt.Errorf("speed %.2f is above Polly's maximum of %.1f", opts.Speed, maxSpeed)
+	}
+	return nil
+}
+
+func (s *synthesizer) MaxInputBytes() int {
+	return maxInputBytes
+}
+
+func (s *synthesizer) TakesMarkup() bool {
+	return s.textType == "ssml"
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string, opts speech.SynthesisOptions) (speech.Audio, error) {
+	textType := s.textType
+	if textType == "ssml" && !strings.HasPrefix(strings.TrimSpace(text), "<speak") {
+		text = "<speak>" + text + "</speak>"
+	}
+	if opts.Speed != 0 && textType == "text" {
+		rate := fmt.Sprintf("%d%%", int(opts.Speed*100))
+		text = "<speak>" + ssml.Render(ssml.Prosody(ssml.ProsodyOptions{Rate: rate}, ssml.Text(text))) + "</speak>"
+		textType = "ssml"
+	}
+
+	voiceID := s.voiceID
+	if opts.Voice != "" {
+		voiceID = opts.Voice
+	}
+
+	requestBody := SynthesizeRequest{
+		Engine:       s.engine,
+		LanguageCode: opts.Language,
+		OutputFormat: s.outputFormat,
+		SampleRate:   s.sampleRate,
+		Text:         text,
+		TextType:     textType,
+		VoiceId:      voiceID,
+	}
+	audioData, err := SynthesizeSpeech(ctx, s.endpoint, s.region, s.credentials, requestBody)
+	if err != nil {
+		return speech.Audio{}, err
+	}
+
+	if s.outputFormat == "pcm" {
+		audioData, err = pcmToWAV(audioData, s.sampleRate)
+		if err != nil {
+			return speech.Audio{}, err
+		}
+		return speech.Audio{Data: audioData, Format: speech.FormatWAV}, nil
+	}
+	return speech.Audio{Data: audioData, Format: speech.FormatMP3}, nil
+}
@@ -0,0 +1,91 @@
+package polly
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The expected signatures are from the AWS Signature Version 4 test suite
+// and the IAM example of the AWS General Reference.
+func TestSignRequest(t *testing.T) {
+	creds := Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		service string
+		want    string
+	}{
+		{
+			name:    "get-vanilla",
+			method:  "GET",
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:    "get-vanilla-query-order-key-case",
+			method:  "GET",
+			url:     "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:    "post-vanilla",
+			method:  "POST",
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:    "iam-list-users",
+			method:  "GET",
+			url:     "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			service: "iam",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			signRequest(req, []byte(test.body), creds, "us-east-1", test.service, now)
+			if got := req.Header.Get("Authorization"); got != test.want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, test.want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s, want 20150830T123600Z", got)
+			}
+		})
+	}
+}
+
+func TestSignRequestSessionToken(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+	signRequest(req, nil, creds, "us-east-1", "service", time.Now())
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Errorf("X-Amz-Security-Token = %q, want %q", got, "token")
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization %q doesn't sign the session token", auth)
+	}
+}
@@ -1,6 +1,7 @@
 package audio
 
 import (
+	"bufio"
 	"bytes"
 	"io"
 	"sync"
@@ -13,25 +14,48 @@ import (
 	"github.com/faiface/beep/wav"
 )
 
+// clip is an entry of the playback queue, either audio to decode or a pause
+// when stream is nil.
+type clip struct {
+	stream io.ReadCloser
+	pause  time.Duration
+}
+
 type AudioPlayer struct {
-	audioQueue      [][]byte
+	audioQueue      []clip
 	mutex           sync.Mutex
 	audioController *beep.Ctrl
+	audioStreamer   beep.StreamSeekCloser
 	doneChannel     chan struct{}
 	audioFormat     beep.Format
 	isAudioPlaying  bool
+	idleSince       time.Time
+	// decodingStream is the stream being decoded outside the lock, closed
+	// by Stop so a stalled stream doesn't hold up the queue
+	decodingStream io.ReadCloser
+	// stopCount lets a clip decoded during Stop be discarded
+	stopCount int
 }
 
 func NewAudioPlayer() *AudioPlayer {
 	return &AudioPlayer{
-		audioQueue:  make([][]byte, 0),
+		audioQueue:  make([]clip, 0),
 		doneChannel: make(chan struct{}),
 	}
 }
 
+// Play queues a complete WAV or MP3 clip for playback.
 func (ap *AudioPlayer) Play(audioData []byte) {
+	ap.PlayStream(io.NopCloser(bytes.NewReader(audioData)))
+}
+
+// PlayStream queues audio that may still be arriving, such as an HTTP
+// response body. Decoding starts as soon as the first bytes are available
+// and the stream is closed once playback finishes.
+func (ap *AudioPlayer) PlayStream(audioStream io.ReadCloser) {
 	if ap == nil {
 		log.Error("AudioPlayer is nil")
+		audioStream.Close()
 		return
 	}
 
@@ -41,10 +65,23 @@ func (ap *AudioPlayer) Play(audioData []byte) {
 		}
 	}()
 
+	ap.enqueue(clip{stream: audioStream})
+}
+
+// PlaySilence queues a pause of the given length after the audio queued so
+// far, such as the silence after a heading.
+func (ap *AudioPlayer) PlaySilence(pause time.Duration) {
+	if ap == nil || pause <= 0 {
+		return
+	}
+	ap.enqueue(clip{pause: pause})
+}
+
+func (ap *AudioPlayer) enqueue(c clip) {
 	ap.mutex.Lock()
 	defer ap.mutex.Unlock()
 
-	ap.audioQueue = append(ap.audioQueue, audioData)
+	ap.audioQueue = append(ap.audioQueue, c)
 
 	if !ap.isAudioPlaying {
 		ap.isAudioPlaying = true
@@ -54,49 +91,85 @@ func (ap *AudioPlayer) Play(audioData []byte) {
 
 func (ap *AudioPlayer) playNextAudioChunk() {
 	ap.mutex.Lock()
-	defer ap.mutex.Unlock()
-
 	if len(ap.audioQueue) == 0 {
-		ap.isAudioPlaying = false
-		close(ap.doneChannel)
+		ap.setIdle()
+		ap.mutex.Unlock()
 		return
 	}
 
-	audioData := ap.audioQueue[0]
+	next := ap.audioQueue[0]
 	ap.audioQueue = ap.audioQueue[1:]
+	if next.stream == nil {
+		defer ap.mutex.Unlock()
+		ap.playSilence(next.pause)
+		return
+	}
 
-	audioReader := bytes.NewReader(audioData)
-	audioReadCloser := io.NopCloser(audioReader)
+	audioStream := next.stream
+	ap.decodingStream = audioStream
+	stopCount := ap.stopCount
+	ap.mutex.Unlock()
 
-	// Determine the format of the audio data
-	var audioStreamer beep.StreamSeekCloser
-	var format beep.Format
-	var err error
+	// Decode outside the lock, since a stream may block until data arrives
+	audioStreamer, format, err := decodeAudio(audioStream)
 
-	if isWAV(audioData) {
-		audioStreamer, format, err = wav.Decode(audioReadCloser)
-	} else {
-		audioStreamer, format, err = mp3.Decode(audioReadCloser)
-	}
+	ap.mutex.Lock()
+	ap.decodingStream = nil
+	ap.mutex.Unlock()
 
 	if err != nil {
 		log.Errorf("Error decoding audio data: %v", err)
+		audioStream.Close()
 		ap.playNextAudioChunkIfAvailable()
 		return
 	}
-	defer audioStreamer.Close()
+
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
+	if ap.stopCount != stopCount {
+		// Stop was called while this clip was being decoded
+		audioStreamer.Close()
+		go ap.playNextAudioChunkIfAvailable()
+		return
+	}
 
 	if ap.audioFormat == (beep.Format{}) {
 		ap.audioFormat = format
 		err = speaker.Init(ap.audioFormat.SampleRate, ap.audioFormat.SampleRate.N(time.Second/10))
 		if err != nil {
 			log.Errorf("Error initializing speaker: %v", err)
-			ap.playNextAudioChunkIfAvailable()
+			audioStreamer.Close()
+			go ap.playNextAudioChunkIfAvailable()
 			return
 		}
 	}
 
-	ap.audioController = &beep.Ctrl{Streamer: audioStreamer, Paused: false}
+	// The speaker runs at the first clip's rate, so resample anything else
+	var streamer beep.Streamer = audioStreamer
+	if format.SampleRate != ap.audioFormat.SampleRate {
+		streamer = beep.Resample(4, format.SampleRate, ap.audioFormat.SampleRate, audioStreamer)
+	}
+
+	ap.audioStreamer = audioStreamer
+	ap.start(streamer, audioStreamer.Close)
+}
+
+// playSilence plays a pause at the speaker's rate. Before the speaker has
+// played anything there is nothing to pause after, so it is skipped. The
+// caller must hold the mutex.
+func (ap *AudioPlayer) playSilence(pause time.Duration) {
+	if ap.audioFormat == (beep.Format{}) {
+		go ap.playNextAudioChunkIfAvailable()
+		return
+	}
+	ap.start(beep.Silence(ap.audioFormat.SampleRate.N(pause)), func() error { return nil })
+}
+
+// start plays streamer and moves on to the next clip once it has finished,
+// calling done first. The caller must hold the mutex.
+func (ap *AudioPlayer) start(streamer beep.Streamer, done func() error) {
+	ap.audioController = &beep.Ctrl{Streamer: streamer, Paused: false}
 
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(1)
@@ -106,6 +179,7 @@ func (ap *AudioPlayer) playNextAudioChunk() {
 
 	go func() {
 		waitGroup.Wait()
+		done()
 		ap.playNextAudioChunkIfAvailable()
 	}()
 }
@@ -118,8 +192,33 @@ func (ap *AudioPlayer) playNextAudioChunkIfAvailable() {
 		ap.isAudioPlaying = true
 		go ap.playNextAudioChunk()
 	} else {
-		ap.isAudioPlaying = false
+		ap.setIdle()
+	}
+}
+
+// setIdle marks the end of playback and wakes WaitForCompletion. The caller
+// must hold the mutex.
+func (ap *AudioPlayer) setIdle() {
+	ap.isAudioPlaying = false
+	ap.idleSince = time.Now()
+	close(ap.doneChannel)
+	ap.doneChannel = make(chan struct{})
+}
+
+// IdleSince reports when the player ran out of queued audio. The boolean is
+// false while audio is still playing or queued.
+func (ap *AudioPlayer) IdleSince() (time.Time, bool) {
+	if ap == nil {
+		return time.Time{}, false
+	}
+
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
+	if ap.isAudioPlaying {
+		return time.Time{}, false
 	}
+	return ap.idleSince, true
 }
 
 func (ap *AudioPlayer) Pause() {
@@ -140,32 +239,65 @@ func (ap *AudioPlayer) Resume() {
 	}
 }
 
+// Stop discards queued audio and ends the current clip. The player becomes
+// idle once the speaker has drained it.
 func (ap *AudioPlayer) Stop() {
+	// Lock in the order clips are started in, player before speaker
+	ap.mutex.Lock()
+	defer ap.mutex.Unlock()
+
 	speaker.Lock()
 	defer speaker.Unlock()
 
-	ap.mutex.Lock()
-	defer ap.mutex.Unlock()
+	for _, queued := range ap.audioQueue {
+		if queued.stream != nil {
+			queued.stream.Close()
+		}
+	}
+	ap.audioQueue = nil
+	if ap.decodingStream != nil {
+		ap.decodingStream.Close()
+	}
+	ap.stopCount++
 
 	if ap.audioController != nil {
-		if closer, ok := ap.audioController.Streamer.(io.Closer); ok {
-			closer.Close()
-		}
-		ap.doneChannel <- struct{}{}
-		ap.isAudioPlaying = false
-		ap.audioQueue = nil
+		// A controller without a streamer is drained, which runs the
+		// playback callback that closes the decoder
+		ap.audioController.Streamer = nil
+		ap.audioController.Paused = false
 	}
 }
 
 func (ap *AudioPlayer) WaitForCompletion() {
-	ap.mutex.Lock()
-	defer ap.mutex.Unlock()
+	if ap == nil {
+		return
+	}
 
+	ap.mutex.Lock()
 	if !ap.isAudioPlaying {
+		ap.mutex.Unlock()
 		return
 	}
+	doneChannel := ap.doneChannel
+	ap.mutex.Unlock()
 
-	<-ap.doneChannel
+	<-doneChannel
+}
+
+// decodeAudio detects whether the stream holds WAV or MP3 data and returns a
+// decoder that reads it incrementally.
+func decodeAudio(audioStream io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	bufferedStream := bufio.NewReader(audioStream)
+	header, _ := bufferedStream.Peek(4)
+	audioReadCloser := struct {
+		io.Reader
+		io.Closer
+	}{bufferedStream, audioStream}
+
+	if isWAV(header) {
+		return wav.Decode(audioReadCloser)
+	}
+	return mp3.Decode(audioReadCloser)
 }
 
 // isWAV checks if the audio data is in WAV format.
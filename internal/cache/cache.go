@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a content-addressed store of synthesized audio on disk. Entries
+// are evicted least recently used first once the total size exceeds the cap.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mutex sync.Mutex
+	// index holds the entries by path and size their total. Both are
+	// loaded by Open, so writes don't have to scan the directory.
+	index  map[string]entry
+	size   int64
+	hits   int64
+	misses int64
+}
+
+// Stats describes the contents and effectiveness of a cache.
+type Stats struct {
+	Dir      string `json:"dir"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	MaxBytes int64  `json:"maxBytes"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+}
+
+// Open creates the cache directory if needed and returns a cache limited
+// to maxBytes, evicting entries if the existing ones exceed it.
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	c := &Cache{dir: dir, maxBytes: maxBytes}
+	index, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	c.index = index
+	for _, entry := range index {
+		c.size += entry.size
+	}
+	if err := c.evict(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Key derives a cache key from the parts that determine the audio, such as
+// provider, voice, settings and text.
+func Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab", "c") and ("a", "bc") differ
+		fmt.Fprintf(hash, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached audio for key and marks it as recently used.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.misses++
+		c.forget(path)
+		return nil, false
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	c.remember(path, entry{size: int64(len(data)), lastUsed: now})
+	c.hits++
+	return data, true
+}
+
+// Put stores audio under key and evicts old entries if the cache is over
+// its size cap.
+func (c *Cache) Put(key string, data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.maxBytes > 0 && int64(len(data)) > c.maxBytes {
+		return nil
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+
+	c.remember(path, entry{size: int64(len(data)), lastUsed: time.Now()})
+	return c.evict()
+}
+
+// Stats reports the number and size of cached entries along with the hits
+// and misses since the cache was opened.
+func (c *Cache) Stats() (Stats, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return Stats{
+		Dir:      c.dir,
+		Entries:  len(c.index),
+		Bytes:    c.size,
+		MaxBytes: c.maxBytes,
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}, nil
+}
+
+// Clear removes every cached entry.
+func (c *Cache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for path := range c.index {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache entry: %v", err)
+		}
+		c.forget(path)
+	}
+	c.hits, c.misses = 0, 0
+	return nil
+}
+
+type entry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// entries lists cached files by path, skipping temporary files of
+// unfinished writes. A file's modification time is when it was last used.
+func (c *Cache) entries() (map[string]entry, error) {
+	entries := make(map[string]entry)
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries[path] = entry{size: info.Size(), lastUsed: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %v", err)
+	}
+	return entries, nil
+}
+
+// remember adds or replaces the entry at path in the index. The caller must
+// hold the mutex.
+func (c *Cache) remember(path string, e entry) {
+	c.forget(path)
+	c.index[path] = e
+	c.size += e.size
+}
+
+// forget drops the entry at path from the index. The caller must hold the
+// mutex.
+func (c *Cache) forget(path string) {
+	if old, ok := c.index[path]; ok {
+		c.size -= old.size
+		delete(c.index, path)
+	}
+}
+
+// evict removes the least recently used entries until the cache fits its
+// cap. The caller must hold the mutex.
+func (c *Cache) evict() error {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return nil
+	}
+
+	paths := make([]string, 0, len(c.index))
+	for path := range c.index {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return c.index[paths[i]].lastUsed.Before(c.index[paths[j]].lastUsed)
+	})
+	for _, path := range paths {
+		if c.size <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict cache entry: %v", err)
+		}
+		c.forget(path)
+	}
+	return nil
+}
+
+// path shards entries into subdirectories by the first two key characters.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
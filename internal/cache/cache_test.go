@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// diskUsage counts the entries and bytes actually in the cache directory.
+func diskUsage(t *testing.T, dir string) (int, int64) {
+	t.Helper()
+	var entries int
+	var bytes int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries++
+		bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries, bytes
+}
+
+// checkSize fails the test unless the tracked size matches the disk and
+// the wanted number of entries.
+func checkSize(t *testing.T, c *Cache, wantEntries int) {
+	t.Helper()
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, bytes := diskUsage(t, c.dir)
+	if stats.Entries != entries || stats.Bytes != bytes {
+		t.Errorf("Stats() = %d entries, %d bytes, disk has %d entries, %d bytes", stats.Entries, stats.Bytes, entries, bytes)
+	}
+	if entries != wantEntries {
+		t.Errorf("disk has %d entries, want %d", entries, wantEntries)
+	}
+}
+
+func mustPut(t *testing.T, c *Cache, key, data string) {
+	t.Helper()
+	if err := c.Put(Key(key), []byte(data)); err != nil {
+		t.Fatalf("Put(%s) error = %v", key, err)
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	c, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get(Key("a")); ok {
+		t.Error("Get() of a missing entry succeeded")
+	}
+	mustPut(t, c, "a", "audio")
+	if data, ok := c.Get(Key("a")); !ok || string(data) != "audio" {
+		t.Errorf("Get() = %q, %v, want audio", data, ok)
+	}
+
+	stats, _ := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %d hits, %d misses, want 1 and 1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() doesn't tell parts apart")
+	}
+	if Key("a", "b") != Key("a", "b") {
+		t.Error("Key() isn't stable")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c, err := Open(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustPut(t, c, "a", "aaaa")
+	mustPut(t, c, "b", "bbbb")
+	c.Get(Key("a"))
+	mustPut(t, c, "c", "cccc")
+
+	// b was used least recently
+	if _, ok := c.Get(Key("b")); ok {
+		t.Error("b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(Key(key)); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+	checkSize(t, c, 2)
+
+	// Entries over the cap are not stored at all
+	mustPut(t, c, "d", "dddddddddddd")
+	checkSize(t, c, 2)
+}
+
+func TestSizeTracking(t *testing.T) {
+	c, err := Open(t.TempDir(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustPut(t, c, "a", "aaaa")
+	mustPut(t, c, "b", "bbbbbbbb")
+	checkSize(t, c, 2)
+
+	// Replacing an entry counts its size once
+	mustPut(t, c, "a", "aa")
+	checkSize(t, c, 2)
+
+	// An entry removed behind the cache's back is forgotten on a miss
+	if err := os.Remove(c.path(Key("b"))); err != nil {
+		t.Fatal(err)
+	}
+	c.Get(Key("b"))
+	checkSize(t, c, 1)
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	checkSize(t, c, 0)
+	if stats, _ := c.Stats(); stats.Bytes != 0 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Stats() after Clear = %+v", stats)
+	}
+}
+
+func TestOpenEvicts(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustPut(t, c, "old", "oooo")
+	mustPut(t, c, "new", "nnnn")
+	// Modification times are when an entry was last used
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(c.path(Key("old")), past, past)
+
+	c, err = Open(dir, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(Key("old")); ok {
+		t.Error("old was not evicted on Open")
+	}
+	if _, ok := c.Get(Key("new")); !ok {
+		t.Error("new was evicted on Open")
+	}
+	checkSize(t, c, 1)
+}
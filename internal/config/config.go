@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"os/user"
@@ -40,6 +41,35 @@ func GetConfig() map[string]interface{} {
 	return cfg
 }
 
+// GetCacheDir returns the directory voxctl uses for cached data, creating it if necessary.
+func GetCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user's cache directory: %v", err)
+	}
+
+	voxctlDir := filepath.Join(cacheDir, "voxctl")
+	if err := os.MkdirAll(voxctlDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating cache directory: %v", err)
+	}
+	return voxctlDir, nil
+}
+
+// GetDataDir returns the directory voxctl uses for data that must survive
+// cache cleanups, creating it if necessary.
+func GetDataDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user's config directory: %v", err)
+	}
+
+	voxctlDir := filepath.Join(configDir, "voxctl")
+	if err := os.MkdirAll(voxctlDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating data directory: %v", err)
+	}
+	return voxctlDir, nil
+}
+
 // readConfig reads and unmarshals the configuration file.
 func readConfig(configFile string, cfg *map[string]interface{}) error {
 	// Read file
@@ -92,3 +122,60 @@ func GetFloat64OrDefault(configData map[string]interface{}, key string, defaultV
 	}
 	return defaultValue
 }
+
+// GetStringSliceOrDefault retrieves a list of strings from the configuration map, or returns a default value if the key is not present or the value is not a list of strings.
+func GetStringSliceOrDefault(cfg map[string]interface{}, key string, defaultValue []string) []string {
+	value, ok := cfg[key]
+	if !ok {
+		return defaultValue
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		log.Printf("Warning: Key %s is not a list. Using default value.", key)
+		return defaultValue
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		strValue, ok := item.(string)
+		if !ok {
+			log.Printf("Warning: Key %s contains a non-string value. Using default value.", key)
+			return defaultValue
+		}
+		result = append(result, strValue)
+	}
+	return result
+}
+
+// GetStringMapOrDefault retrieves an object of string values from the configuration map, or returns a default value if the key is not present or the value is not an object of strings.
+func GetStringMapOrDefault(cfg map[string]interface{}, key string, defaultValue map[string]string) map[string]string {
+	value, ok := cfg[key]
+	if !ok {
+		return defaultValue
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		log.Printf("Warning: Key %s is not an object. Using default value.", key)
+		return defaultValue
+	}
+	result := make(map[string]string, len(object))
+	for name, item := range object {
+		strValue, ok := item.(string)
+		if !ok {
+			log.Printf("Warning: Key %s.%s is not a string. Using default value.", key, name)
+			return defaultValue
+		}
+		result[name] = strValue
+	}
+	return result
+}
+
+// GetMapOrDefault retrieves a JSON object from the configuration map, or returns a default value if the key is not present or the value is not an object.
+func GetMapOrDefault(cfg map[string]interface{}, key string, defaultValue map[string]interface{}) map[string]interface{} {
+	if value, ok := cfg[key]; ok {
+		if mapValue, ok := value.(map[string]interface{}); ok {
+			return mapValue
+		}
+		log.Printf("Warning: Key %s is not an object. Using default value.", key)
+	}
+	return defaultValue
+}
@@ -0,0 +1,222 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/config"
+)
+
+var (
+	clientMutex  sync.RWMutex
+	sharedClient = newClient(settings{
+		connectTimeout: 10 * time.Second,
+		readTimeout:    30 * time.Second,
+		proxy:          http.ProxyFromEnvironment,
+	})
+)
+
+type settings struct {
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	proxy          func(*http.Request) (*url.URL, error)
+	rootCAs        *x509.CertPool
+}
+
+// Client returns the HTTP client shared by all providers. Its transport
+// keeps connections alive between requests.
+func Client() *http.Client {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+	return sharedClient
+}
+
+// DoStream sends a request whose response body is read after the caller has
+// returned, such as audio handed to the player. ctx cancels the request until
+// the response headers arrive; after that only closing the body ends it.
+func DoStream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+
+	resp, err := Client().Do(req.WithContext(streamCtx))
+	if !stop() {
+		// ctx was canceled while waiting for the response
+		cancel()
+		if err == nil {
+			resp.Body.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the context of a streamed response once its body
+// is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Configure replaces the shared client with one built from the HTTP
+// settings in the configuration map:
+//
+//   - HTTPConnectTimeoutSeconds limits connection setup, including TLS (default 10)
+//   - HTTPReadTimeoutSeconds limits how long a response may wait for its headers or for more data (default 30)
+//   - HTTPProxy is a proxy URL; without it the HTTPS_PROXY and NO_PROXY environment variables apply
+//   - HTTPCACertFiles lists PEM files with root certificates to trust in addition to the system ones
+func Configure(cfg map[string]interface{}) error {
+	s := settings{
+		connectTimeout: seconds(config.GetFloat64OrDefault(cfg, "HTTPConnectTimeoutSeconds", 10)),
+		readTimeout:    seconds(config.GetFloat64OrDefault(cfg, "HTTPReadTimeoutSeconds", 30)),
+		proxy:          http.ProxyFromEnvironment,
+	}
+
+	if proxy := config.GetStringOrDefault(cfg, "HTTPProxy", ""); proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("invalid HTTPProxy %q", proxy)
+		}
+		s.proxy = http.ProxyURL(proxyURL)
+	}
+
+	if caFiles := config.GetStringSliceOrDefault(cfg, "HTTPCACertFiles", nil); len(caFiles) > 0 {
+		rootCAs, err := loadRootCAs(caFiles)
+		if err != nil {
+			return err
+		}
+		s.rootCAs = rootCAs
+	}
+
+	client := newClient(s)
+
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+	sharedClient = client
+	return nil
+}
+
+func newClient(s settings) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   s.connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 s.proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       &tls.Config{RootCAs: s.rootCAs},
+		TLSHandshakeTimeout:   s.connectTimeout,
+		ResponseHeaderTimeout: s.readTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          32,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	// No overall timeout, since streamed audio may take longer than any
+	// single read
+	if s.readTimeout <= 0 {
+		return &http.Client{Transport: transport}
+	}
+	return &http.Client{Transport: &readTimeoutTransport{RoundTripper: transport, timeout: s.readTimeout}}
+}
+
+// loadRootCAs adds the certificates in files to the system pool.
+func loadRootCAs(files []string) (*x509.CertPool, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", file)
+		}
+	}
+	return rootCAs, nil
+}
+
+// readTimeoutTransport fails a response body that receives no data within
+// timeout, so a stalled provider cannot block a request forever. The
+// deadline belongs to the request rather than the connection, which may sit
+// idle in the pool for longer.
+type readTimeoutTransport struct {
+	http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *readTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := t.RoundTripper.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	body := &readTimeoutBody{ReadCloser: resp.Body, timeout: t.timeout, cancel: cancel}
+	body.timer = time.AfterFunc(t.timeout, func() {
+		body.timedOut.Store(true)
+		cancel()
+	})
+	// A body waiting in the player's queue isn't stalled, so the timer
+	// only runs during reads
+	body.timer.Stop()
+	resp.Body = body
+	return resp, nil
+}
+
+// readTimeoutBody cancels its request when a read waits longer than timeout
+// for data.
+type readTimeoutBody struct {
+	io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+	cancel   context.CancelFunc
+}
+
+func (b *readTimeoutBody) Read(p []byte) (int, error) {
+	b.timer.Reset(b.timeout)
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Stop()
+	if err != nil && b.timedOut.Load() {
+		return n, fmt.Errorf("no data received for %s", b.timeout)
+	}
+	return n, err
+}
+
+func (b *readTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
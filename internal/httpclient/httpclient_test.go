@@ -0,0 +1,158 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configure applies cfg to the shared client and restores the defaults
+// after the test.
+func configure(t *testing.T, cfg map[string]interface{}) error {
+	t.Cleanup(func() { Configure(map[string]interface{}{}) })
+	return Configure(cfg)
+}
+
+func get(t *testing.T, client *http.Client, url string) (string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/stall" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}))
+	defer server.Close()
+	client := newClient(settings{connectTimeout: time.Second, readTimeout: 50 * time.Millisecond})
+
+	// A stalled body fails once no data arrives for the read timeout
+	if _, err := get(t, client, server.URL+"/stall"); err == nil || !strings.Contains(err.Error(), "no data received for 50ms") {
+		t.Errorf("stalled body error = %v, want the read timeout", err)
+	}
+
+	// but a body that waits before it is read does not
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	time.Sleep(100 * time.Millisecond)
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "partial" {
+		t.Errorf("waiting body = %q, %v, want it read", body, err)
+	}
+}
+
+func TestConfigureProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.Host))
+	}))
+	defer proxy.Close()
+
+	if err := configure(t, map[string]interface{}{"HTTPProxy": proxy.URL}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if body, err := get(t, Client(), "http://speech.example/"); err != nil || body != "proxied speech.example" {
+		t.Errorf("Get() = %q, %v, want it sent through the proxy", body, err)
+	}
+}
+
+func TestConfigureCACertFiles(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("trusted"))
+	}))
+	defer server.Close()
+
+	if _, err := get(t, Client(), server.URL); err == nil {
+		t.Fatal("Get() trusted the test certificate without HTTPCACertFiles")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certificate, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := configure(t, map[string]interface{}{"HTTPCACertFiles": []interface{}{caFile}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if body, err := get(t, Client(), server.URL); err != nil || body != "trusted" {
+		t.Errorf("Get() = %q, %v, want the certificate trusted", body, err)
+	}
+}
+
+func TestConfigureErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("no certificates"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		want string
+	}{
+		{"proxy without host", map[string]interface{}{"HTTPProxy": "proxy.example"}, `invalid HTTPProxy "proxy.example"`},
+		{"missing CA file", map[string]interface{}{"HTTPCACertFiles": []interface{}{filepath.Join(t.TempDir(), "missing.pem")}}, "failed to read CA certificates"},
+		{"CA file without certificates", map[string]interface{}{"HTTPCACertFiles": []interface{}{empty}}, "no certificates found in " + empty},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := configure(t, test.cfg); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Configure() error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestDoStream(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// Canceling ctx before the headers arrive cancels the request
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, _ := http.NewRequest("GET", server.URL+"/slow", nil)
+	if _, err := DoStream(ctx, req); err != context.Canceled {
+		t.Errorf("DoStream() error = %v, want %v", err, context.Canceled)
+	}
+
+	// but not reading the body after DoStream returned
+	ctx, cancel = context.WithCancel(context.Background())
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	resp, err := DoStream(ctx, req)
+	if err != nil {
+		t.Fatalf("DoStream() error = %v", err)
+	}
+	defer resp.Body.Close()
+	cancel()
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "audio" {
+		t.Errorf("body = %q, %v, want it read after the cancel", body, err)
+	}
+}
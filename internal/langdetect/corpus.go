@@ -0,0 +1,130 @@
+package langdetect
+
+// corpus holds sample text used to train the trigram model of each Latin
+// script language. The samples favour everyday vocabulary and function words,
+// which carry most of the signal in short segments.
+var corpus = map[string]string{
+	"en": `The build finished and all of the tests passed. What time is it now?
+I think we should go home because it is getting late. She said that the
+meeting would start in the morning, but nobody knew where it was. There are
+three new messages in your inbox and one of them is from your manager. The
+weather today is cold and windy with a chance of rain in the evening. Please
+remember to check the logs before you deploy the new version. They have been
+working on this project for more than a year and it is almost ready. Would you
+like a cup of coffee or tea? We were waiting for the train when the phone
+rang. This is the first time that I have seen something like that. He could
+not find the keys, so he had to call his brother. Thank you very much for your
+help with the report. Which one of these would you choose if you had the
+chance? Everything is going well and the server is running without errors.
+The children played in the garden while their parents talked about the
+weekend. It was a long day, and everyone was tired when they finally arrived.
+Deployment complete. The request failed with an unexpected error. Your
+download is ready and the update has been installed. Connection lost, trying
+again in a few seconds. The pipeline completed successfully after the second
+attempt. Warning: disk space is running low on the database server. Reminder:
+your appointment is tomorrow afternoon. Battery level is low, please connect
+the charger. The package was delivered to the front door.`,
+
+	"de": `Der Build ist fertig und alle Tests waren erfolgreich. Wie spät ist es
+jetzt? Ich glaube, wir sollten nach Hause gehen, weil es schon spät ist. Sie
+sagte, dass die Besprechung am Morgen beginnen würde, aber niemand wusste, wo
+sie stattfindet. Es gibt drei neue Nachrichten in deinem Posteingang und eine
+davon ist von deinem Chef. Das Wetter ist heute kalt und windig, und am Abend
+könnte es regnen. Bitte denk daran, die Protokolle zu prüfen, bevor du die neue
+Version auslieferst. Sie arbeiten seit mehr als einem Jahr an diesem Projekt
+und es ist fast fertig. Möchtest du eine Tasse Kaffee oder Tee? Wir warteten
+auf den Zug, als das Telefon klingelte. Das ist das erste Mal, dass ich so
+etwas gesehen habe. Er konnte die Schlüssel nicht finden, also musste er
+seinen Bruder anrufen. Vielen Dank für deine Hilfe mit dem Bericht. Welchen
+von diesen würdest du wählen, wenn du die Wahl hättest? Alles läuft gut und
+der Server funktioniert ohne Fehler. Die Kinder spielten im Garten, während
+ihre Eltern über das Wochenende sprachen. Es war ein langer Tag und alle waren
+müde, als sie endlich ankamen. Über die Straße gehen nur Fußgänger.
+Die Bereitstellung ist abgeschlossen. Die Anfrage ist mit einem unerwarteten
+Fehler fehlgeschlagen. Dein Download ist bereit und das Update wurde
+installiert. Verbindung verloren, neuer Versuch in wenigen Sekunden. Die
+Pipeline wurde nach dem zweiten Versuch erfolgreich beendet. Warnung: der
+Speicherplatz auf dem Datenbankserver wird knapp. Erinnerung: dein Termin ist
+morgen Nachmittag. Der Akku ist schwach, bitte schließe das Ladegerät an.`,
+
+	"es": `La compilación ha terminado y todas las pruebas han pasado. ¿Qué hora es
+ahora? Creo que deberíamos ir a casa porque ya es tarde. Ella dijo que la
+reunión empezaría por la mañana, pero nadie sabía dónde era. Hay tres mensajes
+nuevos en tu bandeja de entrada y uno de ellos es de tu jefe. El tiempo hoy es
+frío y con viento, y por la noche puede llover. Por favor, recuerda revisar los
+registros antes de desplegar la nueva versión. Llevan más de un año trabajando
+en este proyecto y ya está casi listo. ¿Quieres una taza de café o de té?
+Estábamos esperando el tren cuando sonó el teléfono. Es la primera vez que veo
+algo así. No encontraba las llaves, así que tuvo que llamar a su hermano.
+Muchas gracias por tu ayuda con el informe. ¿Cuál de estos elegirías si
+tuvieras la oportunidad? Todo va bien y el servidor funciona sin errores. Los
+niños jugaban en el jardín mientras sus padres hablaban del fin de semana. Fue
+un día muy largo y todos estaban cansados cuando por fin llegaron. ¡Qué bueno
+verte otra vez, mañana nos vemos!
+El despliegue se ha completado. La solicitud falló con un error inesperado.
+Tu descarga está lista y la actualización se ha instalado. Se perdió la
+conexión, volviendo a intentar en unos segundos. La canalización terminó
+correctamente después del segundo intento. Advertencia: queda poco espacio en
+el disco del servidor de base de datos. Recordatorio: tu cita es mañana por la
+tarde. La batería está baja, por favor conecta el cargador.`,
+
+	"fr": `La compilation est terminée et tous les tests ont réussi. Quelle heure
+est-il maintenant? Je pense que nous devrions rentrer à la maison parce qu'il
+est déjà tard. Elle a dit que la réunion commencerait le matin, mais personne
+ne savait où elle aurait lieu. Il y a trois nouveaux messages dans ta boîte de
+réception et l'un d'eux vient de ton chef. Le temps est froid et venteux
+aujourd'hui, et il pourrait pleuvoir ce soir. N'oublie pas de vérifier les
+journaux avant de déployer la nouvelle version. Ils travaillent sur ce projet
+depuis plus d'un an et il est presque prêt. Veux-tu une tasse de café ou de
+thé? Nous attendions le train quand le téléphone a sonné. C'est la première
+fois que je vois quelque chose comme ça. Il ne trouvait pas ses clés, alors il
+a dû appeler son frère. Merci beaucoup pour ton aide avec le rapport. Lequel
+choisirais-tu si tu avais le choix? Tout va bien et le serveur fonctionne sans
+erreur. Les enfants jouaient dans le jardin pendant que leurs parents parlaient
+du week-end. C'était une longue journée et tout le monde était fatigué.`,
+
+	"it": `La compilazione è terminata e tutti i test sono stati superati. Che ore
+sono adesso? Penso che dovremmo andare a casa perché è già tardi. Lei ha detto
+che la riunione sarebbe iniziata la mattina, ma nessuno sapeva dove fosse. Ci
+sono tre nuovi messaggi nella tua casella di posta e uno di questi è del tuo
+capo. Il tempo oggi è freddo e ventoso, e stasera potrebbe piovere. Ricordati
+di controllare i registri prima di rilasciare la nuova versione. Lavorano a
+questo progetto da più di un anno ed è quasi pronto. Vuoi una tazza di caffè o
+di tè? Stavamo aspettando il treno quando il telefono ha squillato. È la prima
+volta che vedo qualcosa del genere. Non riusciva a trovare le chiavi, quindi
+ha dovuto chiamare suo fratello. Grazie mille per il tuo aiuto con la
+relazione. Quale di questi sceglieresti se ne avessi la possibilità? Va tutto
+bene e il server funziona senza errori. I bambini giocavano nel giardino mentre
+i loro genitori parlavano del fine settimana. È stata una giornata lunga e
+tutti erano stanchi quando finalmente sono arrivati.`,
+
+	"pt": `A compilação terminou e todos os testes passaram. Que horas são agora?
+Acho que devíamos ir para casa porque já está tarde. Ela disse que a reunião
+começaria de manhã, mas ninguém sabia onde seria. Há três mensagens novas na
+sua caixa de entrada e uma delas é do seu chefe. O tempo hoje está frio e com
+vento, e à noite pode chover. Por favor, lembre-se de verificar os registos
+antes de publicar a nova versão. Eles trabalham neste projeto há mais de um
+ano e já está quase pronto. Quer uma chávena de café ou de chá? Estávamos à
+espera do comboio quando o telefone tocou. É a primeira vez que vejo uma coisa
+assim. Ele não encontrava as chaves, então teve de ligar ao irmão. Muito
+obrigado pela sua ajuda com o relatório. Qual destes escolheria se tivesse a
+oportunidade? Está tudo bem e o servidor funciona sem erros. As crianças
+brincavam no jardim enquanto os pais falavam do fim de semana. Foi um dia
+muito longo e todos estavam cansados quando finalmente chegaram. Não, não
+são as nossas.`,
+
+	"nl": `De build is klaar en alle tests zijn geslaagd. Hoe laat is het nu? Ik
+denk dat we naar huis moeten gaan omdat het al laat is. Ze zei dat de
+vergadering 's ochtends zou beginnen, maar niemand wist waar die was. Er zijn
+drie nieuwe berichten in je inbox en een daarvan is van je baas. Het weer is
+vandaag koud en winderig, en vanavond kan het gaan regenen. Vergeet niet de
+logbestanden te controleren voordat je de nieuwe versie uitrolt. Ze werken al
+meer dan een jaar aan dit project en het is bijna klaar. Wil je een kopje
+koffie of thee? We stonden op de trein te wachten toen de telefoon ging. Het
+is de eerste keer dat ik zoiets zie. Hij kon zijn sleutels niet vinden, dus
+moest hij zijn broer bellen. Heel erg bedankt voor je hulp met het verslag.
+Welke van deze zou jij kiezen als je de kans had? Alles gaat goed en de server
+draait zonder fouten. De kinderen speelden in de tuin terwijl hun ouders over
+het weekend praatten. Het was een lange dag en iedereen was moe toen ze
+eindelijk aankwamen.`,
+}
@@ -0,0 +1,215 @@
+package langdetect
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minConfidence is the probability below which Detect reports no language.
+// Summed over every trigram, the best match quickly approaches certainty,
+// so this only rejects near ties; minTrigrams keeps out short text.
+const minConfidence = 0.9
+
+// minTrigrams is the least number of trigrams, about two short words, that
+// Detect classifies. Shorter text such as "OK." or "Is it?" gets no
+// language, since a few trigrams often favour the wrong one.
+const minTrigrams = 8
+
+// scripts maps writing systems that identify a language on their own. Kana
+// is checked before Han so Japanese text with kanji is not taken as Chinese.
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+type model struct {
+	counts map[string]int
+	total  int
+}
+
+var (
+	models     = trainModels()
+	vocabulary = vocabularySize()
+)
+
+// Detector identifies the language of short pieces of text.
+type Detector struct {
+	languages map[string]bool
+}
+
+// NewDetector creates a detector limited to the given ISO 639-1 language
+// codes. With no languages, every supported language is considered.
+func NewDetector(languages ...string) *Detector {
+	d := &Detector{}
+	if len(languages) > 0 {
+		d.languages = make(map[string]bool, len(languages))
+		for _, language := range languages {
+			d.languages[strings.ToLower(language)] = true
+		}
+	}
+	return d
+}
+
+// Languages returns the sorted codes of all supported languages.
+func Languages() []string {
+	var languages []string
+	for language := range models {
+		languages = append(languages, language)
+	}
+	for _, script := range scripts {
+		if !containsString(languages, script.language) {
+			languages = append(languages, script.language)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Detect returns the ISO 639-1 code of the language of text along with a
+// confidence between 0 and 1. It returns "" if the language is not one the
+// detector considers or the text is too ambiguous to tell.
+func (d *Detector) Detect(text string) (string, float64) {
+	if language, ok := d.detectScript(text); ok {
+		if d.allows(language) {
+			return language, 1
+		}
+		return "", 0
+	}
+
+	trigrams := extractTrigrams(text)
+	if len(trigrams) < minTrigrams {
+		return "", 0
+	}
+
+	scores := make(map[string]float64)
+	best, bestScore := "", math.Inf(-1)
+	for language, m := range models {
+		if !d.allows(language) {
+			continue
+		}
+		score := 0.0
+		for _, trigram := range trigrams {
+			score += math.Log(float64(m.counts[trigram]+1) / float64(m.total+vocabulary))
+		}
+		scores[language] = score
+		if score > bestScore {
+			best, bestScore = language, score
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+
+	// Softmax over the candidates gives the probability of the best match
+	sum := 0.0
+	for _, score := range scores {
+		sum += math.Exp(score - bestScore)
+	}
+	confidence := 1 / sum
+	if confidence < minConfidence {
+		return "", confidence
+	}
+	return best, confidence
+}
+
+func (d *Detector) allows(language string) bool {
+	return d.languages == nil || d.languages[language]
+}
+
+// detectScript reports the language of text written mostly in a script
+// other than Latin.
+func (d *Detector) detectScript(text string) (string, bool) {
+	counts := make(map[string]int)
+	latin, other := 0, 0
+	for _, char := range text {
+		if !unicode.IsLetter(char) {
+			continue
+		}
+		if unicode.Is(unicode.Latin, char) {
+			latin++
+			continue
+		}
+		for _, script := range scripts {
+			if unicode.Is(script.table, char) {
+				counts[script.language]++
+				other++
+				break
+			}
+		}
+	}
+	if other == 0 || other < latin {
+		return "", false
+	}
+
+	if counts["ja"] > 0 {
+		return "ja", true
+	}
+	best, bestCount := "", 0
+	for _, script := range scripts {
+		if counts[script.language] > bestCount {
+			best, bestCount = script.language, counts[script.language]
+		}
+	}
+	return best, true
+}
+
+// extractTrigrams returns the character trigrams of each word, padded with
+// spaces so word beginnings and endings are captured.
+func extractTrigrams(text string) []string {
+	var trigrams []string
+	words := strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.IsLetter(char) && char != '\''
+	})
+	for _, word := range words {
+		runes := []rune(" " + strings.Trim(word, "'") + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			trigrams = append(trigrams, string(runes[i:i+3]))
+		}
+	}
+	return trigrams
+}
+
+func trainModels() map[string]*model {
+	trained := make(map[string]*model, len(corpus))
+	for language, sample := range corpus {
+		m := &model{counts: make(map[string]int)}
+		for _, trigram := range extractTrigrams(sample) {
+			m.counts[trigram]++
+			m.total++
+		}
+		trained[language] = m
+	}
+	return trained
+}
+
+func vocabularySize() int {
+	seen := make(map[string]bool)
+	for _, m := range models {
+		for trigram := range m.counts {
+			seen[trigram] = true
+		}
+	}
+	return len(seen)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The cat sat on the mat.", "en"},
+		{"Der Hund schläft im Garten.", "de"},
+		{"Ich habe heute keine Zeit, aber morgen gerne.", "de"},
+		{"Gracias por todo, amigo.", "es"},
+		{"Merci beaucoup.", "fr"},
+		{"Ciao a tutti.", "it"},
+		{"Das ist gut.", "de"},
+		{"今日は晴れです。", "ja"},
+		{"天气很好。", "zh"},
+		{"안녕하세요", "ko"},
+		{"Привет, как дела?", "ru"},
+		{"OK.", ""},
+		{"Is it?", ""},
+		{"Er ist da.", ""},
+		{"1234 5678", ""},
+		{"", ""},
+	}
+
+	d := NewDetector()
+	for _, test := range tests {
+		got, confidence := d.Detect(test.text)
+		if got != test.want {
+			t.Errorf("Detect(%q) = %q (%.2f), want %q", test.text, got, confidence, test.want)
+		}
+		if got != "" && confidence < minConfidence {
+			t.Errorf("Detect(%q) confidence = %.2f, below %.2f", test.text, confidence, minConfidence)
+		}
+	}
+}
+
+func TestDetectRestricted(t *testing.T) {
+	d := NewDetector("en", "DE")
+	if got, _ := d.Detect("Der Hund schläft im Garten."); got != "de" {
+		t.Errorf("Detect(German) = %q, want de", got)
+	}
+	if got, _ := d.Detect("Gracias por todo, amigo."); got == "es" {
+		t.Error("Detect() returned es, which the detector doesn't consider")
+	}
+	if got, _ := d.Detect("今日は晴れです。"); got != "" {
+		t.Errorf("Detect(Japanese) = %q, want none", got)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	languages := Languages()
+	for _, want := range []string{"de", "en", "ja", "zh"} {
+		if !containsString(languages, want) {
+			t.Errorf("Languages() = %v, missing %s", languages, want)
+		}
+	}
+	for i := 1; i < len(languages); i++ {
+		if languages[i-1] >= languages[i] {
+			t.Errorf("Languages() = %v, not sorted and unique", languages)
+			break
+		}
+	}
+}
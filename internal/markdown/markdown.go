@@ -0,0 +1,241 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kind tells what a block was in the markdown document.
+type Kind int
+
+const (
+	Paragraph Kind = iota
+	Heading
+	ListItem
+	Code
+)
+
+// Block is a heading, paragraph, list item, table row or code block as
+// plain text, with inline markup removed.
+type Block struct {
+	Kind Kind
+	Text string
+}
+
+// CodeMode decides what happens to code blocks.
+type CodeMode string
+
+const (
+	// CodeAnnounce replaces each code block with Options.CodeAnnouncement
+	CodeAnnounce CodeMode = "announce"
+	// CodeSkip leaves code blocks out
+	CodeSkip CodeMode = "skip"
+	// CodeRead reads code blocks as they are
+	CodeRead CodeMode = "read"
+)
+
+// Options control how Parse treats code blocks. CodeAnnouncement is spoken
+// in place of each code block with CodeAnnounce.
+type Options struct {
+	CodeBlocks       CodeMode
+	CodeAnnouncement string
+}
+
+var (
+	fencePattern         = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	atxHeadingPattern    = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	setextPattern        = regexp.MustCompile(`^ {0,3}(?:=+|-+)\s*$`)
+	rulePattern          = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	listItemPattern      = regexp.MustCompile(`^\s*(?:[-*+]|\d{1,9}[.)])\s+(?:\[[ xX]\]\s+)?(.*)$`)
+	quotePattern         = regexp.MustCompile(`^ {0,3}>\s?`)
+	tableRowPattern      = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableDividerPattern  = regexp.MustCompile(`^\s*\|?(?:\s*:?-+:?\s*\|)+\s*(?::?-+:?\s*)?$`)
+	referencePattern     = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*\S+`)
+	htmlCommentPattern   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	indentedCodePattern  = regexp.MustCompile(`^(?: {4}|\t)`)
+	codeSpanPattern      = regexp.MustCompile("(`+)([^`]|[^`].*?[^`])(`+)")
+	imagePattern         = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern          = regexp.MustCompile(`\[([^\]]+)\](?:\([^)]*\)|\[[^\]]*\])`)
+	autolinkPattern      = regexp.MustCompile(`<((?:https?|ftp)://[^>\s]+|mailto:[^>\s]+)>`)
+	urlPattern           = regexp.MustCompile(`\b(?:https?|ftp)://[^\s<>()\[\]]+[^\s<>()\[\].,;:!?'"]`)
+	htmlTagPattern       = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	strongPattern        = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	emphasisPattern      = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	underscorePattern    = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_(\S(?:[^_]*?\S)?)_($|[^\p{L}\p{N}_])`)
+	strikethroughPattern = regexp.MustCompile(`~~(.+?)~~`)
+	escapePattern        = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|>~<])")
+)
+
+// Parse splits a markdown document into the blocks a listener would hear.
+// Links are read by their text and bare URLs by their host name, and rules,
+// link reference definitions and HTML tags are dropped.
+func Parse(document string, opts Options) []Block {
+	p := &parser{opts: opts}
+	lines := strings.Split(strings.ReplaceAll(htmlCommentPattern.ReplaceAllString(document, ""), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for quotePattern.MatchString(line) {
+			line = quotePattern.ReplaceAllString(line, "")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			p.flush()
+			fence := match[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			p.code(code)
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			p.flush()
+		case atxHeadingPattern.MatchString(line):
+			p.flush()
+			p.open(Heading, atxHeadingPattern.FindStringSubmatch(line)[1])
+			p.flush()
+		case setextPattern.MatchString(line) && p.kind == Paragraph && len(p.lines) > 0:
+			p.kind = Heading
+			p.flush()
+		case rulePattern.MatchString(line), referencePattern.MatchString(line):
+			p.flush()
+		case listItemPattern.MatchString(line):
+			p.flush()
+			p.open(ListItem, listItemPattern.FindStringSubmatch(line)[1])
+		case tableRowPattern.MatchString(line):
+			p.flush()
+			if !tableDividerPattern.MatchString(line) {
+				cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+				for j := range cells {
+					cells[j] = strings.TrimSpace(cells[j])
+				}
+				p.open(Paragraph, strings.Join(cells, ", "))
+				p.flush()
+			}
+		case len(p.lines) == 0 && indentedCodePattern.MatchString(line) && p.previous != ListItem:
+			// An indented block not continuing a list item is code
+			code := []string{line}
+			for i+1 < len(lines) && (indentedCodePattern.MatchString(lines[i+1]) || strings.TrimSpace(lines[i+1]) == "") {
+				i++
+				code = append(code, lines[i])
+			}
+			p.code(code)
+		case len(p.lines) > 0:
+			p.lines = append(p.lines, trimmed)
+		default:
+			p.open(Paragraph, trimmed)
+		}
+	}
+	p.flush()
+	return p.blocks
+}
+
+type parser struct {
+	opts   Options
+	blocks []Block
+
+	// kind and lines are the block being collected
+	kind  Kind
+	lines []string
+
+	// previous is the kind of the last block, even if it was empty
+	previous Kind
+}
+
+func (p *parser) open(kind Kind, text string) {
+	p.kind = kind
+	p.lines = []string{strings.TrimSpace(text)}
+}
+
+func (p *parser) flush() {
+	if len(p.lines) == 0 {
+		return
+	}
+	p.add(Block{Kind: p.kind, Text: inline(strings.Join(p.lines, " "))})
+	p.previous = p.kind
+	p.kind = Paragraph
+	p.lines = nil
+}
+
+func (p *parser) code(lines []string) {
+	switch p.opts.CodeBlocks {
+	case CodeSkip:
+	case CodeRead:
+		p.add(Block{Kind: Code, Text: strings.Join(lines, "\n")})
+	default:
+		p.add(Block{Kind: Code, Text: p.opts.CodeAnnouncement})
+	}
+	p.previous = Code
+}
+
+func (p *parser) add(block Block) {
+	block.Text = strings.TrimSpace(block.Text)
+	if block.Text != "" {
+		p.blocks = append(p.blocks, block)
+	}
+}
+
+// inline removes the inline markup of text. Code spans keep their content
+// as is.
+func inline(text string) string {
+	var builder strings.Builder
+	position := 0
+	for _, span := range codeSpanPattern.FindAllStringSubmatchIndex(text, -1) {
+		// A code span needs matching backtick runs
+		if span[3]-span[2] != span[7]-span[6] {
+			continue
+		}
+		builder.WriteString(stripMarkup(text[position:span[0]]))
+		builder.WriteString(strings.TrimSpace(text[span[4]:span[5]]))
+		position = span[1]
+	}
+	builder.WriteString(stripMarkup(text[position:]))
+	return builder.String()
+}
+
+// escapeBase shifts escaped characters into the private use area while the
+// markup is stripped, so they are not taken for markup themselves.
+const escapeBase = 0xE000
+
+func stripMarkup(text string) string {
+	text = escapePattern.ReplaceAllStringFunc(text, func(match string) string {
+		return string(rune(escapeBase + int(match[1])))
+	})
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = autolinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return readURL(match[1 : len(match)-1])
+	})
+	text = urlPattern.ReplaceAllStringFunc(text, readURL)
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = strongPattern.ReplaceAllString(text, "$1$2")
+	text = emphasisPattern.ReplaceAllString(text, "$1")
+	text = underscorePattern.ReplaceAllString(text, "$1$2$3")
+	text = strikethroughPattern.ReplaceAllString(text, "$1")
+	return strings.Map(func(r rune) rune {
+		if r >= escapeBase && r < escapeBase+128 {
+			return r - escapeBase
+		}
+		return r
+	}, text)
+}
+
+// readURL reduces a URL to its host name, or a mailto link to its address.
+func readURL(link string) string {
+	if address, ok := strings.CutPrefix(link, "mailto:"); ok {
+		return address
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
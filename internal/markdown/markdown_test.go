@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	announce := Options{CodeBlocks: CodeAnnounce, CodeAnnouncement: "Code block."}
+	tests := []struct {
+		name     string
+		document string
+		opts     Options
+		want     []Block
+	}{
+		{
+			name:     "atx heading",
+			document: "## Install ##\nRun it.",
+			want:     []Block{{Heading, "Install"}, {Paragraph, "Run it."}},
+		},
+		{
+			name:     "setext heading",
+			document: "Usage\n-----\n\nText here.",
+			want:     []Block{{Heading, "Usage"}, {Paragraph, "Text here."}},
+		},
+		{
+			name:     "paragraph lines",
+			document: "One line\nand the next.\n\nAnother.",
+			want:     []Block{{Paragraph, "One line and the next."}, {Paragraph, "Another."}},
+		},
+		{
+			name:     "links",
+			document: "See [the docs](https://example.com/docs) or [ref][1], <https://www.golang.org/x> and https://github.com/a/b.\n\n[1]: https://example.com",
+			want:     []Block{{Paragraph, "See the docs or ref, golang.org and github.com."}},
+		},
+		{
+			name:     "image and emphasis",
+			document: "![A cat](cat.png) is **very** _cute_ and *small*, ~~not~~ snake_case.",
+			want:     []Block{{Paragraph, "A cat is very cute and small, not snake_case."}},
+		},
+		{
+			name:     "escapes and code spans",
+			document: "Use \\*stars\\* and `a*b*c` here.",
+			want:     []Block{{Paragraph, "Use *stars* and a*b*c here."}},
+		},
+		{
+			name:     "list items",
+			document: "- first item\n  continued\n* [x] done\n1. numbered",
+			want:     []Block{{ListItem, "first item continued"}, {ListItem, "done"}, {ListItem, "numbered"}},
+		},
+		{
+			name:     "table",
+			document: "| Name | Age |\n|------|----:|\n| Ann | 30 |",
+			want:     []Block{{Paragraph, "Name, Age"}, {Paragraph, "Ann, 30"}},
+		},
+		{
+			name:     "quote, rule and comment",
+			document: "> Quoted text\n\n---\n\n<!-- hidden -->After.",
+			want:     []Block{{Paragraph, "Quoted text"}, {Paragraph, "After."}},
+		},
+		{
+			name:     "fenced code announced",
+			document: "Before.\n```go\nfmt.Println()\n```\nAfter.",
+			opts:     announce,
+			want:     []Block{{Paragraph, "Before."}, {Code, "Code block."}, {Paragraph, "After."}},
+		},
+		{
+			name:     "fenced code skipped",
+			document: "Before.\n~~~\nls -la\n~~~\nAfter.",
+			opts:     Options{CodeBlocks: CodeSkip},
+			want:     []Block{{Paragraph, "Before."}, {Paragraph, "After."}},
+		},
+		{
+			name:     "fenced code read",
+			document: "```\nmake build\nmake test\n```",
+			opts:     Options{CodeBlocks: CodeRead},
+			want:     []Block{{Code, "make build\nmake test"}},
+		},
+		{
+			name:     "indented code",
+			document: "Run:\n\n    go build\n\nDone.",
+			opts:     announce,
+			want:     []Block{{Paragraph, "Run:"}, {Code, "Code block."}, {Paragraph, "Done."}},
+		},
+		{
+			name:     "indented list continuation",
+			document: "- item\n\n    more about it",
+			opts:     announce,
+			want:     []Block{{ListItem, "item"}, {Paragraph, "more about it"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Parse(test.document, test.opts); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q) =\n%q\nwant\n%q", test.document, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package normalize
+
+import (
+	"regexp"
+	"strings"
+)
+
+func init() {
+	Register("de", newGerman())
+}
+
+var germanOnes = [...]string{
+	"null", "eins", "zwei", "drei", "vier", "fünf", "sechs", "sieben", "acht", "neun",
+	"zehn", "elf", "zwölf", "dreizehn", "vierzehn", "fünfzehn", "sechzehn", "siebzehn", "achtzehn", "neunzehn",
+}
+
+var germanDigits = [10]string(germanOnes[:10])
+
+var germanTens = [...]string{"", "", "zwanzig", "dreißig", "vierzig", "fünfzig", "sechzig", "siebzig", "achtzig", "neunzig"}
+
+var germanScales = []struct {
+	value uint64
+	one   string
+	many  string
+}{
+	{1e12, "eine Billion", "Billionen"},
+	{1e9, "eine Milliarde", "Milliarden"},
+	{1e6, "eine Million", "Millionen"},
+}
+
+var germanMonths = [...]string{
+	"Januar", "Februar", "März", "April", "Mai", "Juni",
+	"Juli", "August", "September", "Oktober", "November", "Dezember",
+}
+
+// germanOrdinalWords are the ordinals not formed from their cardinal. They
+// are inflected as after "am", as in "am dritten Mai".
+var germanOrdinalWords = map[uint64]string{1: "ersten", 3: "dritten", 7: "siebten", 8: "achten"}
+
+func newGerman() rules {
+	v := &vocabulary{
+		numeral:    `\d{1,3}(?:\.\d{3})+(?:,\d+)?|\d+(?:,\d+)?`,
+		group:      '.',
+		decimal:    ',',
+		point:      "Punkt",
+		minus:      "minus",
+		digits:     germanDigits,
+		readNumber: germanNumber,
+		readYear:   germanYear,
+		yearWords:  []string{"Jahr", "Jahre", "seit", "bis", "von", "ab", "um", "vor", "nach", "anno"},
+		sayAmount:  germanAmount,
+		units: map[string]measure{
+			"km/h": {"ein Kilometer pro Stunde", "Kilometer pro Stunde"},
+			"mph":  {"eine Meile pro Stunde", "Meilen pro Stunde"},
+			"m/s":  {"ein Meter pro Sekunde", "Meter pro Sekunde"},
+			"kWh":  {"eine Kilowattstunde", "Kilowattstunden"},
+			"km":   {"ein Kilometer", "Kilometer"},
+			"cm":   {"ein Zentimeter", "Zentimeter"},
+			"mm":   {"ein Millimeter", "Millimeter"},
+			"m":    {"ein Meter", "Meter"},
+			"kg":   {"ein Kilogramm", "Kilogramm"},
+			"g":    {"ein Gramm", "Gramm"},
+			"mg":   {"ein Milligramm", "Milligramm"},
+			"l":    {"ein Liter", "Liter"},
+			"ml":   {"ein Milliliter", "Milliliter"},
+			"°C":   {"ein Grad Celsius", "Grad Celsius"},
+			"°F":   {"ein Grad Fahrenheit", "Grad Fahrenheit"},
+			"%":    {"ein Prozent", "Prozent"},
+		},
+		currencies: map[string]currency{
+			"€":   {measure{"ein Euro", "Euro"}, measure{"ein Cent", "Cent"}, true},
+			"$":   {measure{"ein Dollar", "Dollar"}, measure{"ein Cent", "Cent"}, true},
+			"£":   {measure{"ein Pfund", "Pfund"}, measure{"ein Penny", "Pence"}, true},
+			"EUR": {measure{"ein Euro", "Euro"}, measure{"ein Cent", "Cent"}, false},
+			"USD": {measure{"ein Dollar", "Dollar"}, measure{"ein Cent", "Cent"}, false},
+			"GBP": {measure{"ein Pfund", "Pfund"}, measure{"ein Penny", "Pence"}, false},
+		},
+	}
+
+	months := strings.Join(germanMonths[:], "|")
+	r := rules{
+		v.minusRule(),
+		{regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`), func(m []string) (string, bool) {
+			return germanDate(m[3], m[2], m[1])
+		}},
+		{regexp.MustCompile(`\b(\d{1,2})\.(\d{1,2})\.(\d{4})\b`), func(m []string) (string, bool) {
+			return germanDate(m[1], m[2], m[3])
+		}},
+		{regexp.MustCompile(`\b(\d{1,2})\.\s?(` + months + `)\b`), func(m []string) (string, bool) {
+			day := parseSmall(m[1])
+			if day < 1 || day > 31 {
+				return "", false
+			}
+			return germanOrdinal(uint64(day)) + " " + m[2], true
+		}},
+		{regexp.MustCompile(`\b(\d{1,2}):(\d{2})(?::(\d{2}))?(?:\s?Uhr\b)?`), germanTime},
+	}
+	r = append(r, v.currencyRules()...)
+	r = append(r, v.unitRule(), v.yearRule(), v.numberRule())
+	return r
+}
+
+// germanCardinal writes numbers below a million as one word, as in
+// "zweitausendvierundzwanzig", and larger ones with separate scale words.
+func germanCardinal(n uint64) string {
+	for _, scale := range germanScales {
+		if n >= scale.value {
+			words := scale.one
+			if count := n / scale.value; count > 1 {
+				words = germanCompound(count) + " " + scale.many
+			}
+			if n%scale.value != 0 {
+				words += " " + germanCardinal(n%scale.value)
+			}
+			return words
+		}
+	}
+
+	if n >= 1000 {
+		words := germanCompound(n/1000) + "tausend"
+		if n%1000 != 0 {
+			words += germanCardinal(n % 1000)
+		}
+		return words
+	}
+	if n >= 100 {
+		words := germanCompound(n/100) + "hundert"
+		if n%100 != 0 {
+			words += germanCardinal(n % 100)
+		}
+		return words
+	}
+	if n >= 20 {
+		words := germanTens[n/10]
+		if n%10 != 0 {
+			words = germanCompound(n%10) + "und" + words
+		}
+		return words
+	}
+	return germanOnes[n]
+}
+
+// germanCompound is the cardinal as the first part of a compound, where
+// "eins" becomes "ein" as in "einhundert".
+func germanCompound(n uint64) string {
+	words := germanCardinal(n)
+	if strings.HasSuffix(words, "eins") {
+		words = strings.TrimSuffix(words, "s")
+	}
+	return words
+}
+
+func germanOrdinal(n uint64) string {
+	if words, ok := germanOrdinalWords[n]; ok {
+		return words
+	}
+	if n < 20 {
+		return germanCardinal(n) + "ten"
+	}
+	return germanCardinal(n) + "sten"
+}
+
+func germanNumber(n number) string {
+	words := germanCardinal(n.integer)
+	if n.fraction != "" {
+		words += " Komma " + readDigits(n.fraction, germanDigits)
+	}
+	return words
+}
+
+// germanYear reads years from 1100 to 1999 in hundreds, as in
+// "neunzehnhundertneunundneunzig".
+func germanYear(year int) (string, bool) {
+	if year < 1100 || year > 1999 {
+		return "", false
+	}
+	words := germanCardinal(uint64(year/100)) + "hundert"
+	if year%100 != 0 {
+		words += germanCardinal(uint64(year % 100))
+	}
+	return words, true
+}
+
+// germanDate reads a date as after "am", as in "dritten Mai
+// zweitausendvierundzwanzig".
+func germanDate(day, month, year string) (string, bool) {
+	d, m, y := parseSmall(day), parseSmall(month), parseSmall(year)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return "", false
+	}
+	yearWords, ok := germanYear(y)
+	if !ok {
+		yearWords = germanCardinal(uint64(y))
+	}
+	return germanOrdinal(uint64(d)) + " " + germanMonths[m-1] + " " + yearWords, true
+}
+
+// germanTime reads "14:30" as "vierzehn Uhr dreißig" and "1:00 Uhr" as
+// "ein Uhr".
+func germanTime(m []string) (string, bool) {
+	hour, minute := parseSmall(m[1]), parseSmall(m[2])
+	if hour > 24 || minute > 59 {
+		return "", false
+	}
+
+	words := germanCompound(uint64(hour)) + " Uhr"
+	if minute > 0 {
+		words += " " + germanCardinal(uint64(minute))
+	}
+	if m[3] != "" {
+		seconds := parseSmall(m[3])
+		if seconds > 59 {
+			return "", false
+		}
+		if seconds == 1 {
+			words += " und eine Sekunde"
+		} else {
+			words += " und " + germanCardinal(uint64(seconds)) + " Sekunden"
+		}
+	}
+	return words, true
+}
+
+// germanAmount reads "12,50 €" as "zwölf Euro fünfzig", the way prices are
+// said. Amounts with more than two decimals are read as decimals.
+func germanAmount(n number, c currency) string {
+	if len(n.fraction) > 2 {
+		return germanNumber(n) + " " + c.unit.many
+	}
+
+	cents := 0
+	if n.fraction != "" {
+		cents = parseSmall((n.fraction + "0")[:2])
+	}
+	if n.integer == 0 && cents > 0 {
+		return readQuantity(number{integer: uint64(cents)}, c.subunit, germanNumber)
+	}
+	words := readQuantity(number{integer: n.integer}, c.unit, germanNumber)
+	if cents > 0 {
+		words += " " + germanCardinal(uint64(cents))
+	}
+	return words
+}
@@ -0,0 +1,252 @@
+package normalize
+
+import (
+	"regexp"
+	"strings"
+)
+
+func init() {
+	Register("en", newEnglish())
+}
+
+var englishOnes = [...]string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var englishDigits = [10]string(englishOnes[:10])
+
+var englishTens = [...]string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = []struct {
+	value uint64
+	word  string
+}{
+	{1e12, "trillion"},
+	{1e9, "billion"},
+	{1e6, "million"},
+	{1e3, "thousand"},
+}
+
+var englishMonths = [...]string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+// englishOrdinalWords are the ordinals not formed by adding "th".
+var englishOrdinalWords = map[string]string{
+	"one": "first", "two": "second", "three": "third", "five": "fifth",
+	"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+}
+
+func newEnglish() rules {
+	v := &vocabulary{
+		numeral:    `\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`,
+		group:      ',',
+		decimal:    '.',
+		point:      "point",
+		minus:      "minus",
+		digits:     englishDigits,
+		readNumber: englishNumber,
+		readYear:   englishYear,
+		yearWords:  []string{"in", "since", "from", "until", "till", "by", "before", "after", "around", "circa", "year"},
+		sayAmount:  englishAmount,
+		units: map[string]measure{
+			"km/h": {"one kilometer per hour", "kilometers per hour"},
+			"mph":  {"one mile per hour", "miles per hour"},
+			"m/s":  {"one meter per second", "meters per second"},
+			"kWh":  {"one kilowatt hour", "kilowatt hours"},
+			"km":   {"one kilometer", "kilometers"},
+			"cm":   {"one centimeter", "centimeters"},
+			"mm":   {"one millimeter", "millimeters"},
+			"m":    {"one meter", "meters"},
+			"mi":   {"one mile", "miles"},
+			"kg":   {"one kilogram", "kilograms"},
+			"g":    {"one gram", "grams"},
+			"mg":   {"one milligram", "milligrams"},
+			"l":    {"one liter", "liters"},
+			"ml":   {"one milliliter", "milliliters"},
+			"°C":   {"one degree Celsius", "degrees Celsius"},
+			"°F":   {"one degree Fahrenheit", "degrees Fahrenheit"},
+			"%":    {"one percent", "percent"},
+		},
+		currencies: map[string]currency{
+			"$":   {measure{"one dollar", "dollars"}, measure{"one cent", "cents"}, true},
+			"€":   {measure{"one euro", "euros"}, measure{"one cent", "cents"}, true},
+			"£":   {measure{"one pound", "pounds"}, measure{"one penny", "pence"}, true},
+			"USD": {measure{"one dollar", "dollars"}, measure{"one cent", "cents"}, false},
+			"EUR": {measure{"one euro", "euros"}, measure{"one cent", "cents"}, false},
+			"GBP": {measure{"one pound", "pounds"}, measure{"one penny", "pence"}, false},
+		},
+	}
+
+	r := rules{
+		v.minusRule(),
+		{regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`), englishDate},
+		{regexp.MustCompile(`\b(\d{1,2}):(\d{2})(?::(\d{2}))?(?:\s?([AaPp])\.?[Mm]\b\.?)?`), englishTime},
+		{regexp.MustCompile(`([$€£])\s?(` + v.numeral + `)\s(million|billion|trillion)\b`), func(m []string) (string, bool) {
+			n, ok := parseNumber(m[2], v.group, v.decimal)
+			if !ok {
+				return "", false
+			}
+			return englishNumber(n) + " " + m[3] + " " + v.currencies[m[1]].unit.many, true
+		}},
+	}
+	r = append(r, v.currencyRules()...)
+	r = append(r,
+		v.unitRule(),
+		rule{regexp.MustCompile(`\b(\d+)(?:st|nd|rd|th)\b`), func(m []string) (string, bool) {
+			n, ok := parseNumber(m[1], v.group, v.decimal)
+			if !ok {
+				return "", false
+			}
+			return englishOrdinal(n.integer), true
+		}},
+		v.yearRule(),
+		v.numberRule(),
+	)
+	return r
+}
+
+func englishCardinal(n uint64) string {
+	switch {
+	case n < 20:
+		return englishOnes[n]
+	case n < 100:
+		words := englishTens[n/10]
+		if n%10 != 0 {
+			words += "-" + englishOnes[n%10]
+		}
+		return words
+	case n < 1000:
+		words := englishOnes[n/100] + " hundred"
+		if n%100 != 0 {
+			words += " " + englishCardinal(n%100)
+		}
+		return words
+	}
+
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			words := englishCardinal(n/scale.value) + " " + scale.word
+			if n%scale.value != 0 {
+				words += " " + englishCardinal(n%scale.value)
+			}
+			return words
+		}
+	}
+	return ""
+}
+
+func englishOrdinal(n uint64) string {
+	words := englishCardinal(n)
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	switch {
+	case englishOrdinalWords[last] != "":
+		last = englishOrdinalWords[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:cut] + last
+}
+
+func englishNumber(n number) string {
+	words := englishCardinal(n.integer)
+	if n.fraction != "" {
+		words += " point " + readDigits(n.fraction, englishDigits)
+	}
+	return words
+}
+
+// englishYear reads years from 1100 to 1999 and from 2010 to 2099 in pairs
+// of digits, as in "nineteen ninety-nine".
+func englishYear(year int) (string, bool) {
+	if year < 1100 || year > 2099 || (year >= 2000 && year < 2010) {
+		return "", false
+	}
+	century, rest := uint64(year/100), uint64(year%100)
+	switch {
+	case rest == 0:
+		return englishCardinal(century) + " hundred", true
+	case rest < 10:
+		return englishCardinal(century) + " oh " + englishCardinal(rest), true
+	}
+	return englishCardinal(century) + " " + englishCardinal(rest), true
+}
+
+func englishDate(m []string) (string, bool) {
+	year, month, day := parseSmall(m[1]), parseSmall(m[2]), parseSmall(m[3])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return "", false
+	}
+	yearWords, ok := englishYear(year)
+	if !ok {
+		yearWords = englishCardinal(uint64(year))
+	}
+	return englishMonths[month-1] + " " + englishOrdinal(uint64(day)) + ", " + yearWords, true
+}
+
+// englishTime reads "14:30" as "fourteen thirty", "9:05" as "nine oh five"
+// and "9:00 am" as "nine a.m.".
+func englishTime(m []string) (string, bool) {
+	hour, minute := parseSmall(m[1]), parseSmall(m[2])
+	if hour > 23 || minute > 59 || (m[4] != "" && (hour < 1 || hour > 12)) {
+		return "", false
+	}
+
+	words := englishCardinal(uint64(hour))
+	switch {
+	case minute == 0 && m[4] != "":
+	case minute == 0 && hour <= 12:
+		words += " o'clock"
+	case minute == 0:
+		words += " hundred"
+	case minute < 10:
+		words += " oh " + englishCardinal(uint64(minute))
+	default:
+		words += " " + englishCardinal(uint64(minute))
+	}
+
+	if m[3] != "" {
+		seconds := parseSmall(m[3])
+		if seconds > 59 {
+			return "", false
+		}
+		words += " and " + englishQuantity(uint64(seconds), "second")
+	}
+	if m[4] != "" {
+		words += " " + strings.ToLower(m[4]) + ".m."
+	}
+	return words, true
+}
+
+// englishAmount reads "$1,200.50" as "one thousand two hundred dollars and
+// fifty cents". Amounts with more than two decimals are read as decimals.
+func englishAmount(n number, c currency) string {
+	if len(n.fraction) > 2 {
+		return englishNumber(n) + " " + c.unit.many
+	}
+
+	cents := 0
+	if n.fraction != "" {
+		cents = parseSmall((n.fraction + "0")[:2])
+	}
+	var words []string
+	if n.integer > 0 || cents == 0 {
+		words = append(words, readQuantity(number{integer: n.integer}, c.unit, englishNumber))
+	}
+	if cents > 0 {
+		words = append(words, readQuantity(number{integer: uint64(cents)}, c.subunit, englishNumber))
+	}
+	return strings.Join(words, " and ")
+}
+
+func englishQuantity(n uint64, unit string) string {
+	if n == 1 {
+		return "one " + unit
+	}
+	return englishCardinal(n) + " " + unit + "s"
+}
@@ -0,0 +1,331 @@
+package normalize
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Normalizer rewrites numbers, dates, times, currencies and units in text
+// as the words a speaker of its language would say.
+type Normalizer interface {
+	Normalize(text string) string
+}
+
+var (
+	registryMutex sync.RWMutex
+	normalizers   = make(map[string]Normalizer)
+)
+
+// Register makes a normalizer available for an ISO 639-1 language code. It
+// is meant to be called from the init function of the file implementing it.
+func Register(language string, normalizer Normalizer) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	normalizers[strings.ToLower(language)] = normalizer
+}
+
+// Lookup returns the normalizer for a language tag, matched by its primary
+// subtag so "de-AT" uses the German normalizer.
+func Lookup(language string) (Normalizer, bool) {
+	primary, _, _ := strings.Cut(strings.ToLower(language), "-")
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	normalizer, ok := normalizers[primary]
+	return normalizer, ok
+}
+
+// Normalize expands text with the normalizer of language. Text in a
+// language without one is returned unchanged.
+func Normalize(language, text string) string {
+	normalizer, ok := Lookup(language)
+	if !ok {
+		return text
+	}
+	return normalizer.Normalize(text)
+}
+
+// rule rewrites each match of pattern. expand receives the submatches and
+// returns false to leave the match as it is.
+type rule struct {
+	pattern *regexp.Regexp
+	expand  func(match []string) (string, bool)
+}
+
+// rules is a Normalizer applying each rule to the whole text in turn, so
+// earlier rules claim their matches before later, more general ones.
+type rules []rule
+
+func (rs rules) Normalize(text string) string {
+	for _, r := range rs {
+		var builder strings.Builder
+		position := 0
+		for _, indexes := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+			match := make([]string, len(indexes)/2)
+			for i := range match {
+				if indexes[2*i] >= 0 {
+					match[i] = text[indexes[2*i]:indexes[2*i+1]]
+				}
+			}
+			expanded, ok := r.expand(match)
+			if !ok {
+				continue
+			}
+			builder.WriteString(text[position:indexes[0]])
+			builder.WriteString(expanded)
+			position = indexes[1]
+		}
+		builder.WriteString(text[position:])
+		text = builder.String()
+	}
+	return text
+}
+
+// minusPattern matches a minus sign starting a number.
+var minusPattern = regexp.MustCompile(`(^|[\s(\[])[-−](\d)`)
+
+// minusRule spells out a leading minus sign before the other rules see the
+// number.
+func (v *vocabulary) minusRule() rule {
+	return rule{minusPattern, func(m []string) (string, bool) {
+		return m[1] + v.minus + " " + m[2], true
+	}}
+}
+
+// number is a parsed numeral. fraction holds the digits after the decimal
+// separator, if any.
+type number struct {
+	integer  uint64
+	fraction string
+}
+
+// maxDigits is the longest integer read as a number rather than digit by
+// digit.
+const maxDigits = 15
+
+// parseNumber parses a numeral using the given group and decimal
+// separators. Groups must be complete thousands, as in "1,200" for English.
+// Integers with leading zeros or too many digits don't parse, so they can
+// be read digit by digit.
+func parseNumber(text string, group, decimal byte) (number, bool) {
+	integerPart, fraction, hasFraction := strings.Cut(text, string(decimal))
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return number{}, false
+	}
+
+	groups := strings.Split(integerPart, string(group))
+	for i, g := range groups {
+		if !isDigits(g) || (i > 0 && len(g) != 3) || (i == 0 && len(groups) > 1 && len(g) > 3) {
+			return number{}, false
+		}
+	}
+	digits := strings.Join(groups, "")
+	if len(digits) > maxDigits || (len(digits) > 1 && digits[0] == '0') {
+		return number{}, false
+	}
+
+	var n number
+	for _, digit := range digits {
+		n.integer = n.integer*10 + uint64(digit-'0')
+	}
+	n.fraction = fraction
+	return n, true
+}
+
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < '0' || text[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSmall parses a short run of digits, such as the parts of a date.
+func parseSmall(text string) int {
+	n := 0
+	for i := 0; i < len(text); i++ {
+		n = n*10 + int(text[i]-'0')
+	}
+	return n
+}
+
+// readDigits reads each digit of text with the given words for 0 to 9.
+func readDigits(text string, words [10]string) string {
+	var read []string
+	for i := 0; i < len(text); i++ {
+		if text[i] >= '0' && text[i] <= '9' {
+			read = append(read, words[text[i]-'0'])
+		}
+	}
+	return strings.Join(read, " ")
+}
+
+// vocabulary is what the rules shared between languages need to know about
+// one of them.
+type vocabulary struct {
+	// numeral matches a number as the language writes it, with group and
+	// decimal as its separators
+	numeral string
+	group   byte
+	decimal byte
+
+	// point reads the dots of numerals that aren't numbers, such as
+	// version numbers
+	point string
+	minus string
+
+	digits [10]string
+
+	readNumber func(n number) string
+	readYear   func(year int) (string, bool)
+
+	// yearWords are the words after which a four digit number is a year,
+	// such as "in" or "since"
+	yearWords []string
+
+	// sayAmount reads an amount of money given the currency it is in
+	sayAmount func(n number, c currency) string
+
+	units      map[string]measure
+	currencies map[string]currency
+}
+
+// measure is how a unit is read after one and after any other quantity.
+type measure struct {
+	one  string
+	many string
+}
+
+// currency names a currency and its hundredth.
+type currency struct {
+	unit     measure
+	subunit  measure
+	symbolic bool
+}
+
+// unitRule expands a number followed by a unit, such as "5km/h".
+func (v *vocabulary) unitRule() rule {
+	pattern := regexp.MustCompile(`\b(` + v.numeral + `)\s?(` + alternation(v.units) + `)($|[^\p{L}\p{N}])`)
+	return rule{pattern, func(m []string) (string, bool) {
+		n, ok := parseNumber(m[1], v.group, v.decimal)
+		if !ok {
+			return "", false
+		}
+		return readQuantity(n, v.units[m[2]], v.readNumber) + m[3], true
+	}}
+}
+
+// currencyRules expand amounts written with a currency symbol before or
+// after them, such as "$1,200.50" or "5 €".
+func (v *vocabulary) currencyRules() []rule {
+	symbols := make(map[string]currency)
+	codes := make(map[string]currency)
+	for symbol, c := range v.currencies {
+		if c.symbolic {
+			symbols[symbol] = c
+		} else {
+			codes[symbol] = c
+		}
+	}
+	expand := func(amount, symbol string) (string, bool) {
+		n, ok := parseNumber(amount, v.group, v.decimal)
+		if !ok {
+			return "", false
+		}
+		return v.sayAmount(n, v.currencies[symbol]), true
+	}
+
+	before := regexp.MustCompile(`(` + alternation(symbols) + `)\s?(` + v.numeral + `)`)
+	after := regexp.MustCompile(`\b(` + v.numeral + `)\s?(` + alternation(symbols) + `|(?:` + alternation(codes) + `)\b)`)
+	return []rule{
+		{before, func(m []string) (string, bool) { return expand(m[2], m[1]) }},
+		{after, func(m []string) (string, bool) { return expand(m[1], m[2]) }},
+	}
+}
+
+// yearRule reads a four digit number after one of v.yearWords as a year,
+// as in "in 1999". Elsewhere it is read as a number, since "1234 lines"
+// isn't a year.
+func (v *vocabulary) yearRule() rule {
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(v.yearWords, "|") + `)(\s+)(\d{4})\b([.,]\d)?`)
+	return rule{pattern, func(m []string) (string, bool) {
+		if m[4] != "" {
+			// A decimal such as "in 1999.5"
+			return "", false
+		}
+		year, ok := v.readYear(parseSmall(m[3]))
+		if !ok {
+			return "", false
+		}
+		return m[1] + m[2] + year, true
+	}}
+}
+
+// numberPattern matches any run of digits, including separators between
+// them.
+var numberPattern = regexp.MustCompile(`\b\d+(?:[.,]\d+)*\b`)
+
+// numberRule reads the numbers left over by the other rules. Integers with
+// leading zeros or too many digits are read digit by digit, and other dotted
+// numerals group by group.
+func (v *vocabulary) numberRule() rule {
+	return rule{numberPattern, func(m []string) (string, bool) {
+		return v.readNumeral(m[0]), true
+	}}
+}
+
+func (v *vocabulary) readNumeral(numeral string) string {
+	if n, ok := parseNumber(numeral, v.group, v.decimal); ok {
+		return v.readNumber(n)
+	}
+	if isDigits(numeral) {
+		return readDigits(numeral, v.digits)
+	}
+
+	var parts []string
+	start := 0
+	for i := 0; i <= len(numeral); i++ {
+		if i < len(numeral) && isDigits(numeral[i:i+1]) {
+			continue
+		}
+		parts = append(parts, v.readNumeral(numeral[start:i]))
+		if i < len(numeral) && numeral[i] == '.' {
+			parts = append(parts, v.point)
+		}
+		start = i + 1
+	}
+	return strings.Join(parts, " ")
+}
+
+// readQuantity reads a number followed by a measure.
+func readQuantity(n number, m measure, readNumber func(n number) string) string {
+	if n.integer == 1 && n.fraction == "" {
+		return m.one
+	}
+	return readNumber(n) + " " + m.many
+}
+
+// alternation is a regular expression matching any key of m, trying longer
+// keys first so "km/h" wins over "km".
+func alternation[V any](m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for i, key := range keys {
+		keys[i] = regexp.QuoteMeta(key)
+	}
+	return strings.Join(keys, "|")
+}
@@ -0,0 +1,58 @@
+package normalize
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// TestGolden normalizes each line of testdata/<language>.input and compares
+// it with the same line of testdata/<language>.golden.
+func TestGolden(t *testing.T) {
+	for _, language := range []string{"en", "de"} {
+		t.Run(language, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", language+".input"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(input), "\n"), "\n")
+			got := make([]string, len(lines))
+			for i, line := range lines {
+				got[i] = Normalize(language, line)
+			}
+
+			goldenPath := filepath.Join("testdata", language+".golden")
+			if *update {
+				if err := os.WriteFile(goldenPath, []byte(strings.Join(got, "\n")+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Split(strings.TrimSuffix(string(golden), "\n"), "\n")
+			if len(want) != len(lines) {
+				t.Fatalf("%s has %d lines, want %d", goldenPath, len(want), len(lines))
+			}
+			for i := range lines {
+				if got[i] != want[i] {
+					t.Errorf("Normalize(%q)\n got: %q\nwant: %q", lines[i], got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, ok := Lookup("de-AT"); !ok {
+		t.Error("Lookup(de-AT) found no normalizer")
+	}
+	if got := Normalize("xx", "5 km"); got != "5 km" {
+		t.Errorf("Normalize(xx) = %q, want the text unchanged", got)
+	}
+}
@@ -0,0 +1,183 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secret values in logs and error messages.
+const redacted = "[REDACTED]"
+
+// minRedactLength keeps very short values, which would match unrelated
+// text, out of the redaction list.
+const minRedactLength = 4
+
+var (
+	mutex    sync.RWMutex
+	resolved = make(map[string]string)
+	secrets  []string
+)
+
+// Get reads a credential from the configuration map. The value is either the
+// secret itself or an object naming where to find it:
+//
+//	{"env": "AZURE_SPEECH_KEY"}             an environment variable
+//	{"file": "~/.config/voxctl/azure.key"}  a file, with surrounding whitespace trimmed
+//	{"command": "pass show voxctl/azure"}   the first line printed by a shell command
+//
+// Resolved values are cached, so a password manager is only asked once,
+// and registered for redaction. A missing key yields an empty string.
+func Get(cfg map[string]interface{}, key string) (string, error) {
+	// Key the cache on the source too, so a changed configuration is honoured
+	source, _ := json.Marshal(cfg[key])
+	cacheKey := key + "=" + string(source)
+
+	mutex.RLock()
+	value, ok := resolved[cacheKey]
+	mutex.RUnlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := resolve(cfg[key])
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", key, err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	resolved[cacheKey] = value
+	register(value)
+	return value, nil
+}
+
+// Register adds a secret obtained at runtime, such as an access token, to
+// the values redacted from logs and error messages.
+func Register(value string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	register(value)
+}
+
+// register adds value to the redaction list once. The caller must hold the
+// mutex.
+func register(value string) {
+	if len(value) < minRedactLength {
+		return
+	}
+	for _, known := range secrets {
+		if known == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+}
+
+func resolve(source interface{}) (string, error) {
+	switch source := source.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return source, nil
+	case map[string]interface{}:
+		if name, ok := source["env"].(string); ok {
+			value, found := os.LookupEnv(name)
+			if !found {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return strings.TrimSpace(value), nil
+		}
+		if path, ok := source["file"].(string); ok {
+			data, err := os.ReadFile(expandHome(path))
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(data)), nil
+		}
+		if command, ok := source["command"].(string); ok {
+			return runCommand(command)
+		}
+		return "", fmt.Errorf("expected an env, file or command entry")
+	default:
+		return "", fmt.Errorf("expected a string or an object, got %T", source)
+	}
+}
+
+// runCommand runs command through the shell and returns the first line of
+// its output, following the convention of password managers like pass.
+func runCommand(command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("command failed: %v: %s", err, message)
+		}
+		return "", fmt.Errorf("command failed: %v", err)
+	}
+
+	firstLine, _, _ := strings.Cut(stdout.String(), "\n")
+	return strings.TrimSpace(firstLine), nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + "/" + rest
+		}
+	}
+	return path
+}
+
+// Redact replaces every known secret in text.
+func Redact(text string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for _, value := range secrets {
+		text = strings.ReplaceAll(text, value, redacted)
+	}
+	return text
+}
+
+// NewRedactingWriter returns a writer that redacts secrets before passing
+// output on to w. It is meant for loggers, which write whole lines at once.
+func NewRedactingWriter(w io.Writer) io.Writer {
+	if file, ok := w.(*os.File); ok {
+		return redactingFile{redactingWriter{file}, file}
+	}
+	return redactingWriter{w}
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactingFile still exposes the file descriptor, so loggers can tell
+// whether they write to a terminal and keep their colors.
+type redactingFile struct {
+	redactingWriter
+	file *os.File
+}
+
+func (r redactingFile) Read(p []byte) (int, error) {
+	return r.file.Read(p)
+}
+
+func (r redactingFile) Fd() uintptr {
+	return r.file.Fd()
+}
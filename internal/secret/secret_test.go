@@ -0,0 +1,111 @@
+package secret
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("VOXCTL_TEST_KEY", " env-secret\n")
+	if err := os.WriteFile(filepath.Join(dir, "key"), []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr string
+	}{
+		{"missing", nil, "", ""},
+		{"plain", "plain-secret", "plain-secret", ""},
+		{"env", map[string]interface{}{"env": "VOXCTL_TEST_KEY"}, "env-secret", ""},
+		{"unset env", map[string]interface{}{"env": "VOXCTL_TEST_UNSET"}, "", "environment variable VOXCTL_TEST_UNSET is not set"},
+		{"file", map[string]interface{}{"file": filepath.Join(dir, "key")}, "file-secret", ""},
+		{"file in home", map[string]interface{}{"file": "~/key"}, "file-secret", ""},
+		{"missing file", map[string]interface{}{"file": filepath.Join(dir, "missing")}, "", "no such file"},
+		{"command", map[string]interface{}{"command": "printf 'command-secret\\nsecond line\\n'"}, "command-secret", ""},
+		{"failing command", map[string]interface{}{"command": "echo locked >&2; exit 1"}, "", "command failed: exit status 1: locked"},
+		{"unknown source", map[string]interface{}{"vault": "key"}, "", "expected an env, file or command entry"},
+		{"wrong type", 42.0, "", "expected a string or an object, got float64"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := map[string]interface{}{}
+			if test.value != nil {
+				cfg["TestKey"] = test.value
+			}
+			got, err := Get(cfg, "TestKey")
+			if test.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), "failed to read TestKey: ") || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("Get() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("Get() = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestGetCachesCommands(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := "echo run >> " + runs + "; echo cached-secret"
+	cfg := map[string]interface{}{"TestKey": map[string]interface{}{"command": command}}
+
+	for i := 0; i < 2; i++ {
+		if got, err := Get(cfg, "TestKey"); err != nil || got != "cached-secret" {
+			t.Fatalf("Get() = %q, %v, want cached-secret", got, err)
+		}
+	}
+	if data, _ := os.ReadFile(runs); strings.Count(string(data), "run") != 1 {
+		t.Errorf("command ran %d times, want once", strings.Count(string(data), "run"))
+	}
+
+	// A changed source is resolved again
+	cfg["TestKey"] = map[string]interface{}{"command": command + "-changed"}
+	if got, _ := Get(cfg, "TestKey"); got != "cached-secret-changed" {
+		t.Errorf("Get() after a change = %q, want cached-secret-changed", got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	t.Setenv("VOXCTL_TEST_REDACT", "resolved-secret")
+	if _, err := Get(map[string]interface{}{"TestKey": map[string]interface{}{"env": "VOXCTL_TEST_REDACT"}}, "TestKey"); err != nil {
+		t.Fatal(err)
+	}
+	Register("runtime-token")
+	Register("abc")
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"key resolved-secret rejected", "key [REDACTED] rejected"},
+		{"Bearer runtime-token", "Bearer [REDACTED]"},
+		{"abc is too short to redact", "abc is too short to redact"},
+		{"nothing secret", "nothing secret"},
+	}
+	for _, test := range tests {
+		if got := Redact(test.text); got != test.want {
+			t.Errorf("Redact(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	line := "token runtime-token expired\n"
+	n, err := NewRedactingWriter(&buf).Write([]byte(line))
+	if err != nil || n != len(line) {
+		t.Errorf("Write() = %d, %v, want %d", n, err, len(line))
+	}
+	if got := buf.String(); got != "token [REDACTED] expired\n" {
+		t.Errorf("written %q, want the token redacted", got)
+	}
+}
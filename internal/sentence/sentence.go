@@ -0,0 +1,163 @@
+package sentence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// nonTerminalAbbreviations never end a sentence, whatever follows them.
+var nonTerminalAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+	"sr": true, "jr": true, "st": true, "mt": true, "rev": true,
+	"gen": true, "col": true, "capt": true, "lt": true, "sgt": true,
+	"vs": true, "cf": true, "approx": true, "ca": true,
+	"e.g": true, "i.e": true, "z.b": true, "bzw": true, "hr": true,
+	"sra": true, "mme": true, "mlle": true,
+}
+
+// numberAbbreviations only continue a sentence when a number follows, as in
+// "No. 5" or "Fig. 3", since "no." also ends sentences.
+var numberAbbreviations = map[string]bool{
+	"no": true, "nr": true, "nos": true, "vol": true, "fig": true,
+	"p": true, "pp": true, "ch": true, "sec": true, "art": true,
+}
+
+// closers may follow the terminal punctuation of a sentence and belong to it.
+const closers = `"')]}»’”」』`
+
+// Split breaks text into sentences, keeping their punctuation. A sentence
+// ends at '.', '!', '?' or an ellipsis followed by whitespace, except after
+// known abbreviations and initials, or when the next word starts in lower
+// case. Decimals and URLs stay intact because their periods are not
+// followed by whitespace. Ideographic full stops end a sentence on their
+// own.
+func Split(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !isTerminal(runes[i]) {
+			continue
+		}
+
+		// Take in runs such as "?!" or "..." and any closing quotes
+		end := i + 1
+		for end < len(runes) && isTerminal(runes[end]) {
+			end++
+		}
+		for end < len(runes) && strings.ContainsRune(closers, runes[end]) {
+			end++
+		}
+
+		if isBoundary(runes, start, i, end) {
+			appendSentence(&sentences, runes[start:end])
+			start = end
+		}
+		i = end - 1
+	}
+
+	appendSentence(&sentences, runes[start:])
+	return sentences
+}
+
+// appendSentence skips fragments without anything to pronounce, such as a
+// stray ellipsis.
+func appendSentence(sentences *[]string, runes []rune) {
+	sentence := strings.TrimSpace(string(runes))
+	if strings.IndexFunc(sentence, isPronounceable) >= 0 {
+		*sentences = append(*sentences, sentence)
+	}
+}
+
+func isPronounceable(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isTerminal(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+func isIdeographicTerminal(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+// isBoundary decides whether the punctuation at runes[mark:end] ends the
+// sentence that began at start.
+func isBoundary(runes []rune, start, mark, end int) bool {
+	if isIdeographicTerminal(runes[mark]) {
+		return true
+	}
+	if end == len(runes) {
+		return true
+	}
+	if !unicode.IsSpace(runes[end]) {
+		return false
+	}
+
+	next := end
+	for next < len(runes) && unicode.IsSpace(runes[next]) {
+		next++
+	}
+	if next == len(runes) {
+		return true
+	}
+	if unicode.IsLower(runes[next]) {
+		// "e.g. the", "wait... what" and "“Why?” she asked" continue
+		return false
+	}
+
+	// Only a lone period can belong to an abbreviation
+	if runes[mark] != '.' || end-mark > 1 && isTerminal(runes[mark+1]) {
+		return true
+	}
+	word := previousWord(runes, start, mark)
+	if unicode.IsDigit(runes[next]) && numberAbbreviations[strings.ToLower(word)] {
+		return false
+	}
+	return !isAbbreviation(word)
+}
+
+// previousWord returns the word directly before the period at mark.
+func previousWord(runes []rune, start, mark int) string {
+	wordStart := mark
+	for wordStart > start && !unicode.IsSpace(runes[wordStart-1]) {
+		wordStart--
+	}
+	return strings.TrimLeft(string(runes[wordStart:mark]), `"'(«“‘`)
+}
+
+func isAbbreviation(word string) bool {
+	if word == "" {
+		return false
+	}
+
+	// Initials such as the "J" in "J. R. R. Tolkien"
+	letters := []rune(word)
+	if len(letters) == 1 && unicode.IsUpper(letters[0]) {
+		return true
+	}
+	if isInitialism(letters) {
+		return true
+	}
+	return nonTerminalAbbreviations[strings.ToLower(word)]
+}
+
+// isInitialism reports whether word is single letters separated by periods,
+// such as the "U.S" of "U.S. Army". A sentence ending in one runs on into the
+// next, since the period can't be told apart from the one that ends it.
+func isInitialism(letters []rune) bool {
+	if len(letters) < 3 || len(letters)%2 == 0 {
+		return false
+	}
+	for i, r := range letters {
+		if i%2 == 0 && !unicode.IsLetter(r) || i%2 == 1 && r != '.' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package sentence
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "title",
+			text: "Dr. Smith is in. Please wait.",
+			want: []string{"Dr. Smith is in.", "Please wait."},
+		},
+		{
+			name: "decimal",
+			text: "Pi is about 3.14. That is enough.",
+			want: []string{"Pi is about 3.14.", "That is enough."},
+		},
+		{
+			name: "latin abbreviation",
+			text: "Bring fruit, e.g. Apples or pears. Thanks.",
+			want: []string{"Bring fruit, e.g. Apples or pears.", "Thanks."},
+		},
+		{
+			name: "url",
+			text: "See https://example.com/a.b?c=d. It has more.",
+			want: []string{"See https://example.com/a.b?c=d.", "It has more."},
+		},
+		{
+			name: "ellipsis continuing",
+			text: "Wait... what? Yes.",
+			want: []string{"Wait... what?", "Yes."},
+		},
+		{
+			name: "ellipsis ending",
+			text: "I wonder… Maybe not.",
+			want: []string{"I wonder…", "Maybe not."},
+		},
+		{
+			name: "closing quote",
+			text: `He said "Stop." Then he left.`,
+			want: []string{`He said "Stop."`, "Then he left."},
+		},
+		{
+			name: "question in quotes",
+			text: "“Why?” she asked. Nobody knew.",
+			want: []string{"“Why?” she asked.", "Nobody knew."},
+		},
+		{
+			name: "number abbreviation",
+			text: "Track No. 5 is next. The answer is no. We go on.",
+			want: []string{"Track No. 5 is next.", "The answer is no.", "We go on."},
+		},
+		{
+			name: "initials",
+			text: "J. R. R. Tolkien wrote it. Read it.",
+			want: []string{"J. R. R. Tolkien wrote it.", "Read it."},
+		},
+		{
+			name: "initialism",
+			text: "The U.S. Army marched. They rested.",
+			want: []string{"The U.S. Army marched.", "They rested."},
+		},
+		{
+			name: "ideographic",
+			text: "今日は晴れです。明日は雨です。",
+			want: []string{"今日は晴れです。", "明日は雨です。"},
+		},
+		{
+			name: "ends in multibyte rune",
+			text: "Ça va. Très bien, merci ça",
+			want: []string{"Ça va.", "Très bien, merci ça"},
+		},
+		{
+			name: "stray punctuation",
+			text: "Done. ...",
+			want: []string{"Done."},
+		},
+		{
+			name: "empty",
+			text: "  ",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Split(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Split(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/secret"
 	"github.com/ln64-git/voxctl/internal/speech"
 	"github.com/ln64-git/voxctl/internal/types"
 )
@@ -18,6 +22,8 @@ func StartServer(state types.AppState) {
 	port := state.ClientPort
 	log.Infof("Starting server on port %d", port)
 
+	var inFlight speechRequests
+
 	http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -30,18 +36,104 @@ func StartServer(state types.AppState) {
 		inputReq, err := processSpeechRequest(r)
 		if err != nil {
 			log.Errorf("%v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		err = speech.ProcessSpeech(*inputReq, state)
+		// The request is canceled by /stop or when the client disconnects
+		ctx, finish := inFlight.start(r.Context())
+		defer finish()
+
+		err = speech.ProcessSpeech(ctx, *inputReq, state)
 		if err != nil {
 			log.Errorf("%v", err)
+			writeSpeechError(w, err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
 	})
 
+	http.HandleFunc("/voices", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		refresh, _ := strconv.ParseBool(query.Get("refresh"))
+		filter := speech.VoiceFilter{
+			Provider: query.Get("provider"),
+			Language: query.Get("language"),
+			Gender:   query.Get("gender"),
+			Refresh:  refresh,
+		}
+
+		voices, err := speech.ListVoices(state.Config, filter)
+		if err != nil {
+			log.Errorf("%v", err)
+			writeSpeechError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(voices); err != nil {
+			log.Errorf("Failed to encode voices: %v", err)
+		}
+	})
+
+	http.HandleFunc("/usage", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		reports, err := speech.Usage(state.Config)
+		if err != nil {
+			log.Errorf("%v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reports); err != nil {
+			log.Errorf("Failed to encode usage: %v", err)
+		}
+	})
+
+	http.HandleFunc("/cache/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		stats, err := speech.CacheStats(state.Config)
+		if err != nil {
+			log.Errorf("%v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			log.Errorf("Failed to encode cache stats: %v", err)
+		}
+	})
+
+	http.HandleFunc("/cache/clear", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := speech.ClearCache(state.Config); err != nil {
+			log.Errorf("%v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
 	http.HandleFunc("/pause", func(w http.ResponseWriter, r *http.Request) {
 		if state.AudioPlayer != nil {
 			state.AudioPlayer.Pause()
@@ -52,6 +144,11 @@ func StartServer(state types.AppState) {
 	})
 
 	http.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		// Cancel synthesis first so no further segments reach the player
+		if canceled := inFlight.cancelAll(); canceled > 0 {
+			log.Infof("Canceled %d speech request(s)", canceled)
+		}
+
 		if state.AudioPlayer != nil {
 			state.AudioPlayer.Stop()
 			w.WriteHeader(http.StatusOK)
@@ -93,6 +190,104 @@ func ConnectToServer(port int) (*http.Response, error) {
 	return resp, nil
 }
 
+// speechRequests tracks the /input requests being processed so /stop can
+// cancel them.
+type speechRequests struct {
+	mutex    sync.Mutex
+	nextID   int
+	requests map[int]speechRequest
+}
+
+type speechRequest struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start derives a cancelable context for a request. The returned function
+// must be called once the request is finished.
+func (s *speechRequests) start(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	request := speechRequest{cancel: cancel, done: make(chan struct{})}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.requests == nil {
+		s.requests = make(map[int]speechRequest)
+	}
+	id := s.nextID
+	s.nextID++
+	s.requests[id] = request
+
+	return ctx, func() {
+		s.mutex.Lock()
+		delete(s.requests, id)
+		s.mutex.Unlock()
+		cancel()
+		close(request.done)
+	}
+}
+
+// cancelAll cancels every request in flight and waits for them to stop, so
+// none of them queues more audio afterwards. It returns how many were
+// canceled.
+func (s *speechRequests) cancelAll() int {
+	s.mutex.Lock()
+	requests := make([]speechRequest, 0, len(s.requests))
+	for _, request := range s.requests {
+		request.cancel()
+		requests = append(requests, request)
+	}
+	s.mutex.Unlock()
+
+	for _, request := range requests {
+		<-request.done
+	}
+	return len(requests)
+}
+
+// ErrorResponse is the JSON body returned when a speech or voice request
+// fails. Category is one of invalid_request, budget, auth, quota,
+// rate_limited, invalid_voice, transient, rejected, canceled or internal.
+type ErrorResponse struct {
+	Error    string `json:"error"`
+	Category string `json:"category"`
+}
+
+// StatusClientClosedRequest is the nonstandard status nginx uses for
+// requests abandoned before a response was sent.
+const StatusClientClosedRequest = 499
+
+// writeSpeechError reports err with a status code matching its category.
+func writeSpeechError(w http.ResponseWriter, err error) {
+	category := speech.ErrorCategory(err)
+
+	status := http.StatusInternalServerError
+	switch category {
+	case "invalid_request", "invalid_voice":
+		status = http.StatusBadRequest
+	case "budget", "quota", "rate_limited":
+		status = http.StatusTooManyRequests
+	case "transient":
+		status = http.StatusServiceUnavailable
+	case "auth", "rejected":
+		status = http.StatusBadGateway
+	case "canceled":
+		status = StatusClientClosedRequest
+	}
+
+	var providerErr *speech.ProviderError
+	if errors.As(err, &providerErr) && providerErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(providerErr.RetryAfter.Round(time.Second)/time.Second)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	message := secret.Redact(err.Error())
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: message, Category: category}); err != nil {
+		log.Errorf("Failed to encode error: %v", err)
+	}
+}
+
 func processSpeechRequest(r *http.Request) (*speech.SpeechRequest, error) {
 	var req speech.SpeechRequest
 	bodyBytes, err := io.ReadAll(r.Body)
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/speech"
+)
+
+func TestWriteSpeechError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCategory   string
+		wantRetryAfter string
+	}{
+		{"invalid request", &speech.InvalidRequestError{Reason: "text is empty"}, http.StatusBadRequest, "invalid_request", ""},
+		{"invalid voice", &speech.ProviderError{Kind: speech.ErrorInvalidVoice}, http.StatusBadRequest, "invalid_voice", ""},
+		{"budget", &speech.BudgetExceededError{Provider: "Google", Period: "daily"}, http.StatusTooManyRequests, "budget", ""},
+		{"quota", &speech.ProviderError{Kind: speech.ErrorQuota}, http.StatusTooManyRequests, "quota", ""},
+		{"rate limited", &speech.ProviderError{Kind: speech.ErrorRateLimited, RetryAfter: 1500 * time.Millisecond}, http.StatusTooManyRequests, "rate_limited", "2"},
+		{"transient", &speech.ProviderError{Kind: speech.ErrorTransient}, http.StatusServiceUnavailable, "transient", ""},
+		{"auth", &speech.ProviderError{Kind: speech.ErrorAuth}, http.StatusBadGateway, "auth", ""},
+		{"rejected", &speech.ProviderError{Kind: speech.ErrorRejected}, http.StatusBadGateway, "rejected", ""},
+		{"canceled", fmt.Errorf("failed to send request: %w", context.Canceled), StatusClientClosedRequest, "canceled", ""},
+		{"internal", errors.New("boom"), http.StatusInternalServerError, "internal", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			writeSpeechError(recorder, test.err)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if got := recorder.Header().Get("Retry-After"); got != test.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, test.wantRetryAfter)
+			}
+			var response ErrorResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Category != test.wantCategory || response.Error != test.err.Error() {
+				t.Errorf("response = %+v, want category %s and %q", response, test.wantCategory, test.err)
+			}
+		})
+	}
+}
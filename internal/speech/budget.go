@@ -0,0 +1,209 @@
+package speech
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/usage"
+)
+
+var (
+	usageLedgerOnce sync.Once
+	usageLedger     *usage.Ledger
+	usageLedgerErr  error
+)
+
+// Budget actions, set per provider in UsageBudgets.
+const (
+	budgetRefuse   = "refuse"
+	budgetWarn     = "warn"
+	budgetFallback = "fallback"
+)
+
+// BudgetExceededError reports that a request would push a provider past
+// its daily or monthly character budget.
+type BudgetExceededError struct {
+	Provider string
+	Period   string
+	Used     int64
+	Limit    int64
+
+	// fallback lets the failover chain move on to the next provider
+	fallback bool
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s %s character budget exhausted (%d of %d used)", e.Provider, e.Period, e.Used, e.Limit)
+}
+
+type budget struct {
+	daily   int64
+	monthly int64
+	action  string
+}
+
+// UsageReport is the character usage of a provider alongside its budgets.
+// A budget of zero means unlimited.
+type UsageReport struct {
+	Provider      string `json:"provider"`
+	Today         int64  `json:"today"`
+	Month         int64  `json:"month"`
+	DailyBudget   int64  `json:"dailyBudget,omitempty"`
+	MonthlyBudget int64  `json:"monthlyBudget,omitempty"`
+}
+
+// openUsageLedger returns the shared usage ledger, loading it on first use.
+func openUsageLedger() (*usage.Ledger, error) {
+	usageLedgerOnce.Do(func() {
+		dataDir, err := config.GetDataDir()
+		if err != nil {
+			usageLedgerErr = err
+			return
+		}
+		usageLedger, usageLedgerErr = usage.Open(filepath.Join(dataDir, "usage.json"))
+	})
+	return usageLedger, usageLedgerErr
+}
+
+// loadBudgets reads UsageBudgets, an object mapping provider names to their
+// Daily and Monthly character limits and the Action to take once a limit is
+// reached.
+func loadBudgets(cfg map[string]interface{}) map[string]budget {
+	budgets := make(map[string]budget)
+	for provider, value := range config.GetMapOrDefault(cfg, "UsageBudgets", nil) {
+		settings, ok := value.(map[string]interface{})
+		if !ok {
+			log.Warnf("Ignoring UsageBudgets entry for %s: expected an object", provider)
+			continue
+		}
+
+		b := budget{
+			daily:   int64(config.GetFloat64OrDefault(settings, "Daily", 0)),
+			monthly: int64(config.GetFloat64OrDefault(settings, "Monthly", 0)),
+			action:  strings.ToLower(config.GetStringOrDefault(settings, "Action", budgetRefuse)),
+		}
+		switch b.action {
+		case budgetRefuse, budgetWarn, budgetFallback:
+		default:
+			log.Warnf("Unknown UsageBudgets action %q for %s, refusing requests over budget", b.action, provider)
+			b.action = budgetRefuse
+		}
+		budgets[provider] = b
+	}
+	return budgets
+}
+
+// check returns an error if sending characters more to provider would exceed
+// the budget. With the warn action the overrun is only logged.
+func (b budget) check(provider string, totals usage.Totals, characters int64) error {
+	var exceeded *BudgetExceededError
+	switch {
+	case b.daily > 0 && totals.Today+characters > b.daily:
+		exceeded = &BudgetExceededError{Provider: provider, Period: "daily", Used: totals.Today, Limit: b.daily}
+	case b.monthly > 0 && totals.Month+characters > b.monthly:
+		exceeded = &BudgetExceededError{Provider: provider, Period: "monthly", Used: totals.Month, Limit: b.monthly}
+	default:
+		return nil
+	}
+
+	if b.action == budgetWarn {
+		log.Warnf("%v", exceeded)
+		return nil
+	}
+	exceeded.fallback = b.action == budgetFallback
+	return exceeded
+}
+
+// reserveCharacters charges text against the budget of the link's provider
+// before it is sent, and returns a function that gives the characters back
+// if synthesis fails. Checking and recording under the ledger's lock keeps
+// concurrent prefetches from overrunning the budget together.
+func (l chainLink) reserveCharacters(text string) (func(), error) {
+	characters := int64(utf8.RuneCountInString(text))
+	if l.ledger == nil {
+		return func() {}, nil
+	}
+
+	at := time.Now()
+	err := l.ledger.Reserve(l.name, characters, at, func(totals usage.Totals) error {
+		return l.budget.check(l.name, totals, characters)
+	})
+	var budgetErr *BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		return nil, err
+	}
+	if err != nil {
+		log.Warnf("Failed to record usage: %v", err)
+	}
+
+	return func() {
+		if err := l.ledger.Record(l.name, -characters, at); err != nil {
+			log.Warnf("Failed to record usage: %v", err)
+		}
+	}, nil
+}
+
+// checkBudgets rejects a request up front if a provider refusing requests
+// over budget can't afford all of it, rather than failing once part of it
+// was spoken. Segments that turn out to be cached are counted too.
+func (s *speakerSynthesizers) checkBudgets(segments []segment) error {
+	characters := make(map[string]int64)
+	primaries := make(map[string]chainLink)
+	for _, seg := range segments {
+		link := s.chains[seg.speaker].links[0]
+		if link.ledger == nil || link.budget.action != budgetRefuse {
+			continue
+		}
+		characters[link.name] += int64(utf8.RuneCountInString(seg.text))
+		primaries[link.name] = link
+	}
+
+	now := time.Now()
+	for name, link := range primaries {
+		if err := link.budget.check(name, link.ledger.Totals(name, now), characters[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Usage reports the characters sent to each provider today and this month,
+// including providers that have a budget but no usage yet.
+func Usage(cfg map[string]interface{}) ([]UsageReport, error) {
+	ledger, err := openUsageLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reports := make(map[string]*UsageReport)
+	for provider, totals := range ledger.All(now) {
+		reports[provider] = &UsageReport{Provider: provider, Today: totals.Today, Month: totals.Month}
+	}
+	for provider, b := range loadBudgets(cfg) {
+		report, ok := reports[provider]
+		if !ok {
+			report = &UsageReport{Provider: provider}
+			reports[provider] = report
+		}
+		report.DailyBudget = b.daily
+		report.MonthlyBudget = b.monthly
+	}
+
+	list := make([]UsageReport, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, *report)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Provider < list[j].Provider
+	})
+	return list, nil
+}
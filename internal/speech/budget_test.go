@@ -0,0 +1,145 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/cache"
+	"github.com/ln64-git/voxctl/internal/usage"
+)
+
+func openTestLedger(t *testing.T) *usage.Ledger {
+	t.Helper()
+	ledger, err := usage.Open(filepath.Join(t.TempDir(), "usage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ledger
+}
+
+func TestBudgetCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		budget       budget
+		totals       usage.Totals
+		characters   int64
+		wantPeriod   string
+		wantFallback bool
+	}{
+		{name: "under budget", budget: budget{daily: 100, action: budgetRefuse}, totals: usage.Totals{Today: 50}, characters: 50},
+		{name: "unlimited", budget: budget{action: budgetRefuse}, totals: usage.Totals{Today: 1e9, Month: 1e9}, characters: 1},
+		{name: "over daily", budget: budget{daily: 100, action: budgetRefuse}, totals: usage.Totals{Today: 50, Month: 50}, characters: 51, wantPeriod: "daily"},
+		{name: "over monthly", budget: budget{monthly: 1000, action: budgetRefuse}, totals: usage.Totals{Today: 0, Month: 990}, characters: 20, wantPeriod: "monthly"},
+		{name: "warn", budget: budget{daily: 100, action: budgetWarn}, totals: usage.Totals{Today: 100}, characters: 1},
+		{name: "fallback", budget: budget{daily: 100, action: budgetFallback}, totals: usage.Totals{Today: 100}, characters: 1, wantPeriod: "daily", wantFallback: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.budget.check("Google", test.totals, test.characters)
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) {
+				if test.wantPeriod != "" || err != nil {
+					t.Errorf("check() error = %v, want %s budget exceeded", err, test.wantPeriod)
+				}
+				return
+			}
+			if budgetErr.Period != test.wantPeriod || budgetErr.fallback != test.wantFallback {
+				t.Errorf("check() = %+v, want period %q and fallback %v", budgetErr, test.wantPeriod, test.wantFallback)
+			}
+		})
+	}
+}
+
+func TestBudgetActions(t *testing.T) {
+	tests := []struct {
+		name         string
+		action       string
+		wantProvider string
+		wantErr      string
+	}{
+		{name: "refuse", action: budgetRefuse, wantErr: "budget"},
+		{name: "warn", action: budgetWarn, wantProvider: "a"},
+		{name: "fallback", action: budgetFallback, wantProvider: "b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetCooldowns()
+			primary, fallback := &fakeSynthesizer{}, &fakeSynthesizer{}
+			chain := newTestChain(retryPolicy{}, primary, fallback)
+			chain.links[0].ledger = openTestLedger(t)
+			chain.links[0].budget = budget{daily: 5, action: test.action}
+
+			_, provider, err := chain.synthesize(context.Background(), "Hello world.", "")
+			if test.wantErr != "" {
+				if ErrorCategory(err) != test.wantErr {
+					t.Errorf("synthesize() error = %v, want %s", err, test.wantErr)
+				}
+				if len(primary.calls())+len(fallback.calls()) != 0 {
+					t.Error("a provider was called")
+				}
+				return
+			}
+			if err != nil || provider != test.wantProvider {
+				t.Errorf("synthesize() = %q, %v, want %q", provider, err, test.wantProvider)
+			}
+		})
+	}
+}
+
+func TestBudgetCharges(t *testing.T) {
+	audioCache, err := cache.Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSynthesizer{errs: []error{&ProviderError{Kind: ErrorRejected}}}
+	link := chainLink{name: "fake", synthesizer: fake, cache: audioCache, ledger: openTestLedger(t)}
+	today := func() int64 {
+		return link.ledger.Totals("fake", time.Now()).Today
+	}
+
+	// A failed request gives its characters back
+	if _, err := link.synthesize(context.Background(), "Hello.", ""); err == nil {
+		t.Fatal("synthesize() succeeded, want the rejection")
+	}
+	if got := today(); got != 0 {
+		t.Errorf("failed request charged %d characters", got)
+	}
+
+	// A cache hit costs nothing
+	for i := 0; i < 2; i++ {
+		if _, err := link.synthesize(context.Background(), "Hello.", ""); err != nil {
+			t.Fatalf("synthesize() error = %v", err)
+		}
+	}
+	if got := today(); got != 6 {
+		t.Errorf("charged %d characters, want 6", got)
+	}
+	if calls := fake.calls(); len(calls) != 2 {
+		t.Errorf("provider called %d times, want 2", len(calls))
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	chain := newTestChain(retryPolicy{}, &fakeSynthesizer{})
+	chain.links[0].ledger = openTestLedger(t)
+	chain.links[0].budget = budget{daily: 10, action: budgetRefuse}
+	s := &speakerSynthesizers{chains: map[string]*failoverSynthesizer{"": chain}}
+
+	// Each segment fits on its own, but not all of them together
+	if err := s.checkBudgets([]segment{{text: "Hello."}, {text: "World."}}); ErrorCategory(err) != "budget" {
+		t.Errorf("checkBudgets() error = %v, want budget", err)
+	}
+	if err := s.checkBudgets([]segment{{text: "Hello."}}); err != nil {
+		t.Errorf("checkBudgets() error = %v", err)
+	}
+
+	chain.links[0].budget.action = budgetWarn
+	if err := s.checkBudgets([]segment{{text: "Hello."}, {text: "World."}}); err != nil {
+		t.Errorf("checkBudgets() with warn error = %v", err)
+	}
+}
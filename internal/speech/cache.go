@@ -0,0 +1,155 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/cache"
+	"github.com/ln64-git/voxctl/internal/config"
+)
+
+var (
+	audioCacheOnce sync.Once
+	audioCache     *cache.Cache
+	audioCacheErr  error
+)
+
+// openAudioCache returns the shared audio cache, opening it on first use.
+// It returns nil when AudioCache is disabled.
+func openAudioCache(cfg map[string]interface{}) (*cache.Cache, error) {
+	if !config.GetBoolOrDefault(cfg, "AudioCache", true) {
+		return nil, nil
+	}
+
+	audioCacheOnce.Do(func() {
+		cacheDir, err := config.GetCacheDir()
+		if err != nil {
+			audioCacheErr = err
+			return
+		}
+		maxMB := config.GetFloat64OrDefault(cfg, "AudioCacheMaxMB", 200)
+		audioCache, audioCacheErr = cache.Open(filepath.Join(cacheDir, "audio"), int64(maxMB*1024*1024))
+	})
+	return audioCache, audioCacheErr
+}
+
+// CacheStats reports the size and hit rate of the audio cache.
+func CacheStats(cfg map[string]interface{}) (cache.Stats, error) {
+	audioCache, err := openAudioCache(cfg)
+	if err != nil {
+		return cache.Stats{}, err
+	}
+	if audioCache == nil {
+		return cache.Stats{}, fmt.Errorf("audio cache is disabled")
+	}
+	return audioCache.Stats()
+}
+
+// ClearCache removes all cached audio.
+func ClearCache(cfg map[string]interface{}) error {
+	audioCache, err := openAudioCache(cfg)
+	if err != nil {
+		return err
+	}
+	if audioCache == nil {
+		return fmt.Errorf("audio cache is disabled")
+	}
+	return audioCache.Clear()
+}
+
+// providerSettings fingerprints the configuration of a provider, which by
+// convention lives in keys prefixed with its name. Credentials don't affect
+// the audio and are left out.
+func providerSettings(name string, cfg map[string]interface{}) string {
+	return fingerprintSettings(name, cfg, func(key string) bool {
+		return !isCredentialKey(key)
+	})
+}
+
+// fingerprintSettings lists the settings of a provider for which keep
+// returns true, in a stable order.
+func fingerprintSettings(name string, cfg map[string]interface{}, keep func(key string) bool) string {
+	var keys []string
+	for key := range cfg {
+		if strings.HasPrefix(key, name) && keep(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var settings strings.Builder
+	for _, key := range keys {
+		value, _ := json.Marshal(cfg[key])
+		fmt.Fprintf(&settings, "%s=%s;", key, value)
+	}
+	return settings.String()
+}
+
+func isCredentialKey(key string) bool {
+	for _, marker := range []string{"Key", "Secret", "Token"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// audioCacheKey identifies the audio a provider produces for text with the
+// given options and settings.
+func audioCacheKey(provider, settings, text string, opts SynthesisOptions) string {
+	normalizedText := strings.Join(strings.Fields(text), " ")
+	return cache.Key(
+		provider,
+		settings,
+		opts.Voice,
+		opts.Language,
+		opts.Gender,
+		fmt.Sprintf("%g", opts.Speed),
+		normalizedText,
+	)
+}
+
+// cachedAudio wraps a cache entry, detecting its format from the header.
+func cachedAudio(data []byte) Audio {
+	if bytes.HasPrefix(data, []byte("RIFF")) {
+		return Audio{Data: data, Format: FormatWAV}
+	}
+	return Audio{Data: data, Format: FormatMP3}
+}
+
+// cacheWriter copies a stream into the cache as it is played. The entry is
+// only stored if the stream was read to the end. Close may be called while a
+// Read is in progress, as AudioPlayer.Stop does.
+type cacheWriter struct {
+	io.ReadCloser
+	cache    *cache.Cache
+	key      string
+	buffer   bytes.Buffer
+	complete atomic.Bool
+}
+
+func (w *cacheWriter) Read(p []byte) (int, error) {
+	n, err := w.ReadCloser.Read(p)
+	w.buffer.Write(p[:n])
+	if err == io.EOF {
+		w.complete.Store(true)
+	}
+	return n, err
+}
+
+func (w *cacheWriter) Close() error {
+	if w.complete.Swap(false) {
+		if err := w.cache.Put(w.key, w.buffer.Bytes()); err != nil {
+			log.Warnf("Failed to cache audio: %v", err)
+		}
+	}
+	return w.ReadCloser.Close()
+}
@@ -0,0 +1,65 @@
+package speech
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/cache"
+)
+
+func TestCacheWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		read      int
+		wantCache bool
+	}{
+		{name: "read to the end", read: -1, wantCache: true},
+		{name: "partly read", read: 4},
+		{name: "not read", read: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			audioCache, err := cache.Open(t.TempDir(), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := &cacheWriter{ReadCloser: io.NopCloser(strings.NewReader("complete audio")), cache: audioCache, key: "key"}
+
+			if test.read < 0 {
+				io.ReadAll(w)
+			} else {
+				io.ReadFull(w, make([]byte, test.read))
+			}
+			w.Close()
+
+			data, ok := audioCache.Get("key")
+			if ok != test.wantCache {
+				t.Fatalf("cached = %v, want %v", ok, test.wantCache)
+			}
+			if ok && string(data) != "complete audio" {
+				t.Errorf("cached %q, want the whole stream", data)
+			}
+		})
+	}
+}
+
+func TestAudioCacheKey(t *testing.T) {
+	opts := SynthesisOptions{Voice: "alloy"}
+	key := audioCacheKey("OpenAI", "settings", "Hello  world.", opts)
+
+	if audioCacheKey("OpenAI", "settings", "Hello world.", opts) != key {
+		t.Error("key depends on whitespace")
+	}
+	for _, other := range []string{
+		audioCacheKey("Azure", "settings", "Hello world.", opts),
+		audioCacheKey("OpenAI", "other", "Hello world.", opts),
+		audioCacheKey("OpenAI", "settings", "Hello world.", SynthesisOptions{Voice: "echo"}),
+		audioCacheKey("OpenAI", "settings", "Hello world.", SynthesisOptions{Voice: "alloy", Speed: 1.5}),
+	} {
+		if other == key {
+			t.Error("key ignores a difference in provider, settings or options")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/ssml"
+)
+
+// chunker groups sentences into the pieces of text sent to a provider.
+// Short sentences are merged up to target characters so a provider sees
+// whole thoughts, and only text over the provider's limit is split.
+type chunker struct {
+	target      int
+	firstTarget int
+}
+
+// newChunker reads SpeechChunkSize (default 300 characters) and
+// SpeechFirstChunkSize (default 0, off), which keeps the first chunk of an
+// input short so playback starts sooner.
+func newChunker(cfg map[string]interface{}) chunker {
+	return chunker{
+		target:      int(config.GetFloat64OrDefault(cfg, "SpeechChunkSize", 300)),
+		firstTarget: int(config.GetFloat64OrDefault(cfg, "SpeechFirstChunkSize", 0)),
+	}
+}
+
+// chunk merges consecutive sentences of the same block, speaker and language
+// and splits those over their speaker's limit, in bytes as reported by
+// failoverSynthesizer.maxInputBytes. The first chunk honours firstTarget.
+// Markup segments are passed through as they are.
+func (c chunker) chunk(sentences []segment, limits map[string]int) []segment {
+	var pieces []segment
+	for _, seg := range sentences {
+		if seg.markup {
+			pieces = append(pieces, seg)
+			continue
+		}
+		texts := splitToLimit(seg.text, limits[seg.speaker])
+		for i, text := range texts {
+			piece := seg
+			piece.text = text
+			if i < len(texts)-1 {
+				// Pause after the last piece only
+				piece.pause = 0
+			}
+			pieces = append(pieces, piece)
+		}
+	}
+
+	target := c.target
+	if c.firstTarget > 0 && len(pieces) > 0 {
+		target = c.firstTarget
+		if first := pieces[0]; !first.markup && utf8.RuneCountInString(first.text) > target {
+			// Start on a clause rather than a whole long sentence
+			if packed := pack(splitClauses(first.text), target, 0); len(packed) > 1 {
+				head, tail := first, first
+				head.text = packed[0]
+				head.pause = 0
+				tail.text = strings.Join(packed[1:], " ")
+				pieces = append([]segment{head, tail}, pieces[1:]...)
+			}
+		}
+	}
+
+	var chunks []segment
+	for _, piece := range pieces {
+		if n := len(chunks); n > 0 {
+			current := &chunks[n-1]
+			merged := current.text + " " + piece.text
+			sameVoice := current.speaker == piece.speaker && current.language == piece.language
+			plain := !current.markup && !piece.markup
+			if current.block == piece.block && sameVoice && plain && fits(merged, target, limits[piece.speaker]) {
+				current.text = merged
+				current.pause = piece.pause
+				continue
+			}
+			target = c.target
+		}
+		chunks = append(chunks, piece)
+	}
+	return chunks
+}
+
+// fits reports whether text is within target characters and limit bytes.
+// A zero target or limit is unbounded.
+func fits(text string, target, limit int) bool {
+	if limit > 0 && inputBytes(text) > limit {
+		return false
+	}
+	return target <= 0 || utf8.RuneCountInString(text) <= target
+}
+
+// inputBytes is the length of text as measured against an input limit. It
+// counts text escaped for SSML, since Azure, Google and Polly send it that
+// way and "&" alone takes five bytes as "&amp;".
+func inputBytes(text string) int {
+	return len(ssml.Render(ssml.Text(text)))
+}
+
+// splitToLimit splits a sentence longer than limit bytes at clause
+// boundaries, then between words, and as a last resort mid-word.
+func splitToLimit(s string, limit int) []string {
+	if limit <= 0 || inputBytes(s) <= limit {
+		return []string{s}
+	}
+
+	var pieces []string
+	for _, clause := range pack(splitClauses(s), 0, limit) {
+		if inputBytes(clause) <= limit {
+			pieces = append(pieces, clause)
+			continue
+		}
+		for _, words := range pack(strings.Fields(clause), 0, limit) {
+			for inputBytes(words) > limit {
+				cut := min(limit, len(words))
+				for cut > 0 && (cut < len(words) && !utf8.RuneStart(words[cut]) || inputBytes(words[:cut]) > limit) {
+					cut--
+				}
+				if cut == 0 {
+					// The limit is below a single rune, which is sent anyway
+					_, cut = utf8.DecodeRuneInString(words)
+				}
+				pieces = append(pieces, words[:cut])
+				words = words[cut:]
+			}
+			if words != "" {
+				pieces = append(pieces, words)
+			}
+		}
+	}
+	return pieces
+}
+
+// pack joins consecutive parts with spaces while the result fits. Parts that
+// don't fit on their own are returned unchanged.
+func pack(parts []string, target, limit int) []string {
+	var packed []string
+	current := ""
+	for _, part := range parts {
+		if current != "" && !fits(current+" "+part, target, limit) {
+			packed = append(packed, current)
+			current = ""
+		}
+		if current == "" {
+			current = part
+		} else {
+			current += " " + part
+		}
+	}
+	if current != "" {
+		packed = append(packed, current)
+	}
+	return packed
+}
+
+// splitClauses splits a sentence after commas, semicolons, colons and dashes
+// that are followed by whitespace, keeping the punctuation with its clause.
+func splitClauses(s string) []string {
+	var clauses []string
+	runes := []rune(s)
+	start := 0
+	for i, r := range runes {
+		if !strings.ContainsRune(",;:—–", r) || i+1 >= len(runes) || !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		if clause := strings.TrimSpace(string(runes[start : i+1])); clause != "" {
+			clauses = append(clauses, clause)
+		}
+		start = i + 1
+	}
+	if clause := strings.TrimSpace(string(runes[start:])); clause != "" {
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
@@ -0,0 +1,142 @@
+package speech
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func texts(segments []segment) []string {
+	var texts []string
+	for _, seg := range segments {
+		texts = append(texts, seg.text)
+	}
+	return texts
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunker chunker
+		input   []segment
+		limits  map[string]int
+		want    []string
+	}{
+		{
+			name:    "merges up to target",
+			chunker: chunker{target: 20},
+			input:   []segment{{text: "One."}, {text: "Two."}, {text: "Three and four."}},
+			want:    []string{"One. Two.", "Three and four."},
+		},
+		{
+			name:    "keeps blocks apart",
+			chunker: chunker{target: 100},
+			input:   []segment{{text: "Title", block: 0}, {text: "Body.", block: 1}},
+			want:    []string{"Title", "Body."},
+		},
+		{
+			name:    "keeps speakers and languages apart",
+			chunker: chunker{target: 100},
+			input:   []segment{{text: "Hi."}, {text: "Hallo.", language: "de"}, {text: "Yo.", speaker: "bob"}},
+			want:    []string{"Hi.", "Hallo.", "Yo."},
+		},
+		{
+			name:    "splits over the limit at clauses",
+			chunker: chunker{target: 100},
+			input:   []segment{{text: "First part, second part, third part."}},
+			limits:  map[string]int{"": 25},
+			want:    []string{"First part, second part,", "third part."},
+		},
+		{
+			name:    "short first chunk",
+			chunker: chunker{target: 100, firstTarget: 15},
+			input:   []segment{{text: "Right away, then the rest of it."}, {text: "More."}},
+			want:    []string{"Right away,", "then the rest of it. More."},
+		},
+		{
+			name:    "first sentence of one clause",
+			chunker: chunker{target: 100, firstTarget: 5},
+			input:   []segment{{text: "Unbroken sentence."}, {text: "Next."}},
+			want:    []string{"Unbroken sentence.", "Next."},
+		},
+		{
+			name:    "markup as it is",
+			chunker: chunker{target: 10, firstTarget: 5},
+			input:   []segment{{text: "<speak>One. Two. Three.</speak>", markup: true}, {text: "Next."}},
+			want:    []string{"<speak>One. Two. Three.</speak>", "Next."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := texts(test.chunker.chunk(test.input, test.limits)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("chunk() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChunkKeepsLastPause(t *testing.T) {
+	input := []segment{{text: "Heading, with a clause.", pause: time.Second}}
+	chunks := chunker{}.chunk(input, map[string]int{"": 12})
+	for i, chunk := range chunks {
+		want := time.Duration(0)
+		if i == len(chunks)-1 {
+			want = time.Second
+		}
+		if chunk.pause != want {
+			t.Errorf("chunk %d %q has pause %s, want %s", i, chunk.text, chunk.pause, want)
+		}
+	}
+}
+
+func TestSplitToLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"fits", "Short.", 10, []string{"Short."}},
+		{"unlimited", "Any length at all.", 0, []string{"Any length at all."}},
+		{"words", "alpha beta gamma delta", 11, []string{"alpha beta", "gamma delta"}},
+		{"mid-word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"multibyte", "äöüß", 3, []string{"ä", "ö", "ü", "ß"}},
+		{"limit below a rune", "日本", 2, []string{"日", "本"}},
+		{"escaped", "a & b & c", 9, []string{"a & b", "& c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitToLimit(test.text, test.limit)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("splitToLimit(%q, %d) = %q, want %q", test.text, test.limit, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPack(t *testing.T) {
+	parts := []string{"aa", "bb", "cc", "dddddd"}
+	if got, want := pack(parts, 5, 0), []string{"aa bb", "cc", "dddddd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pack() by target = %q, want %q", got, want)
+	}
+	if got, want := pack(parts, 0, 8), []string{"aa bb cc", "dddddd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pack() by limit = %q, want %q", got, want)
+	}
+	if got := pack(nil, 5, 5); got != nil {
+		t.Errorf("pack(nil) = %q, want nil", got)
+	}
+}
+
+func TestSplitClauses(t *testing.T) {
+	got := splitClauses("One, two; three: four — five, 3,5 end")
+	want := []string{"One,", "two;", "three:", "four —", "five,", "3,5 end"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitClauses() = %q, want %q", got, want)
+	}
+	if strings.Join(got, " ") != "One, two; three: four — five, 3,5 end" {
+		t.Error("splitClauses() lost text")
+	}
+}
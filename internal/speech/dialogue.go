@@ -0,0 +1,220 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/sentence"
+)
+
+// speakerTagPattern matches dialogue markup such as "[narrator]". Only
+// matches at the start of a span count, see isSpanStart.
+var speakerTagPattern = regexp.MustCompile(`\[([A-Za-z][A-Za-z0-9_-]*)\]`)
+
+// segment is a piece of text to synthesize along with the speaker it is
+// attributed to and its language. An empty speaker means the default voice.
+// Segments of different input blocks are never merged, and pause is the
+// silence to leave after the segment. Markup segments are SSML for a
+// provider that takes it, and are neither split nor merged.
+type segment struct {
+	text     string
+	speaker  string
+	language string
+	block    int
+	pause    time.Duration
+	markup   bool
+}
+
+// speakerSpan is a run of text between two speaker tags.
+type speakerSpan struct {
+	speaker string
+	text    string
+}
+
+// parseSpeakers splits text at speaker tags. Text before the first tag is
+// attributed to speaker, and the speaker of the end of text is returned so
+// the next block can continue with it.
+func parseSpeakers(text, speaker string) ([]speakerSpan, string) {
+	var spans []speakerSpan
+	position := 0
+	for _, match := range speakerTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		// A tag directly after another one starts a span too
+		followsTag := position > 0 && strings.TrimSpace(text[position:match[0]]) == ""
+		if !followsTag && !isSpanStart(text[:match[0]]) {
+			continue
+		}
+		if spanText := strings.TrimSpace(text[position:match[0]]); spanText != "" {
+			spans = append(spans, speakerSpan{speaker: speaker, text: spanText})
+		}
+		speaker = strings.ToLower(text[match[2]:match[3]])
+		position = match[1]
+	}
+	if spanText := strings.TrimSpace(text[position:]); spanText != "" {
+		spans = append(spans, speakerSpan{speaker: speaker, text: spanText})
+	}
+	return spans, speaker
+}
+
+// isSpanStart reports whether a speaker tag after before starts a line or
+// follows the end of a sentence, so brackets in ordinary text such as
+// "array[index]" or a markdown "[link]" are left alone.
+func isSpanStart(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	if before == "" || strings.HasSuffix(before, "\n") {
+		return true
+	}
+	before = strings.TrimRight(before, `"')]}»’”」』`)
+	last, _ := utf8.DecodeLastRuneInString(before)
+	return strings.ContainsRune(".!?…。！？", last)
+}
+
+// loadSpeakerProfiles reads the "Speakers" object of the configuration,
+// keyed by lower-case speaker name. Each profile may set VoiceService,
+// Voice, Language, Gender and Speed.
+func loadSpeakerProfiles(cfg map[string]interface{}) map[string]SpeechRequest {
+	profiles := make(map[string]SpeechRequest)
+	for name, value := range config.GetMapOrDefault(cfg, "Speakers", nil) {
+		profile, ok := value.(map[string]interface{})
+		if !ok {
+			log.Warnf("Ignoring speaker %s: profile is not an object", name)
+			continue
+		}
+		profiles[strings.ToLower(name)] = SpeechRequest{
+			Provider: config.GetStringOrDefault(profile, "VoiceService", ""),
+			Voice:    config.GetStringOrDefault(profile, "Voice", ""),
+			Language: config.GetStringOrDefault(profile, "Language", ""),
+			Gender:   config.GetStringOrDefault(profile, "Gender", ""),
+			Speed:    config.GetFloat64OrDefault(profile, "Speed", 0),
+		}
+	}
+	return profiles
+}
+
+// speakerSynthesizers builds one provider chain per speaker so each segment
+// is spoken with its speaker's voice profile.
+type speakerSynthesizers struct {
+	chains map[string]*failoverSynthesizer
+}
+
+// newSpeakerSynthesizers splits the input blocks into speaker-attributed
+// segments, sized to fit their speaker's providers, and builds the chains
+// they need. Speaker profiles override the request, and unknown speakers
+// fall back to the request's default voice.
+func newSpeakerSynthesizers(req SpeechRequest, defaultProvider string, blocks []inputBlock, cfg map[string]interface{}) (*speakerSynthesizers, []segment, error) {
+	if req.Provider == "" {
+		req.Provider = defaultProvider
+	}
+	if _, err := req.options(); err != nil {
+		return nil, nil, err
+	}
+	profiles := loadSpeakerProfiles(cfg)
+	s := &speakerSynthesizers{chains: make(map[string]*failoverSynthesizer)}
+
+	detector := newLanguageDetector(cfg)
+	normalizing := config.GetBoolOrDefault(cfg, "TextNormalization", true)
+	if req.Normalize != nil {
+		normalizing = *req.Normalize
+	}
+	limits := make(map[string]int)
+	var segments []segment
+	tagged := ""
+	for index, block := range blocks {
+		var spans []speakerSpan
+		spans, tagged = parseSpeakers(block.text, tagged)
+		first := len(segments)
+		for _, span := range spans {
+			speaker := span.speaker
+			if _, known := profiles[speaker]; !known && speaker != "" {
+				log.Warnf("Unknown speaker %q, using the default voice", speaker)
+				speaker = ""
+			}
+
+			speakerReq := req
+			if speaker != "" {
+				speakerReq = applyProfile(req, profiles[speaker])
+			}
+			if _, built := s.chains[speaker]; !built {
+				opts, err := speakerReq.options()
+				if err != nil {
+					return nil, nil, speakerError(speaker, err)
+				}
+
+				chain, err := newFailoverSynthesizer(speakerReq.Provider, cfg, opts)
+				if err != nil {
+					return nil, nil, speakerError(speaker, err)
+				}
+				s.chains[speaker] = chain
+				limits[speaker] = chain.maxInputBytes()
+			}
+
+			spanText := SanitizeInput(span.text)
+			if s.chains[speaker].takesMarkup() {
+				if limit := limits[speaker]; limit > 0 && len(spanText) > limit {
+					return nil, nil, speakerError(speaker, invalidRequestf("SSML input of %d bytes is over the input limit of %d bytes", len(spanText), limit))
+				}
+				segments = append(segments, segment{text: spanText, speaker: speaker, language: speakerReq.Language, block: index, markup: true})
+				continue
+			}
+			if normalizing {
+				spanText = normalizeText(spanText, speakerReq.Language, s.chains[speaker].voiceLanguage(), detector, cfg)
+			}
+
+			// A language hint on the request or profile overrides detection
+			for _, segmentText := range sentence.Split(spanText) {
+				segments = append(segments, segment{text: segmentText, speaker: speaker, language: speakerReq.Language, block: index})
+			}
+		}
+		if block.pause > 0 && len(segments) > first {
+			segments[len(segments)-1].pause = block.pause
+		}
+	}
+
+	if detector != nil {
+		detectSegmentLanguages(detector, segments)
+	}
+	return s, newChunker(cfg).chunk(segments, limits), nil
+}
+
+func (s *speakerSynthesizers) synthesize(ctx context.Context, seg segment) (Audio, string, error) {
+	return s.chains[seg.speaker].synthesize(ctx, seg.text, seg.language)
+}
+
+// applyProfile overlays the non-empty fields of a speaker profile on the
+// request. A profile that switches provider drops the request's voice,
+// since voice names are provider specific.
+func applyProfile(req, profile SpeechRequest) SpeechRequest {
+	if profile.Provider != "" && profile.Provider != req.Provider {
+		req.Provider = profile.Provider
+		req.Voice = ""
+		req.Gender = ""
+	}
+	if profile.Voice != "" {
+		req.Voice = profile.Voice
+	}
+	if profile.Language != "" {
+		req.Language = profile.Language
+	}
+	if profile.Gender != "" {
+		req.Gender = profile.Gender
+	}
+	if profile.Speed != 0 {
+		req.Speed = profile.Speed
+	}
+	return req
+}
+
+func speakerError(speaker string, err error) error {
+	if speaker == "" {
+		return err
+	}
+	if invalidErr, ok := err.(*InvalidRequestError); ok {
+		return &InvalidRequestError{Reason: fmt.Sprintf("speaker %s: %s", speaker, invalidErr.Reason)}
+	}
+	return fmt.Errorf("speaker %s: %v", speaker, err)
+}
@@ -0,0 +1,155 @@
+package speech
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSpeakers(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		speaker     string
+		want        []speakerSpan
+		wantSpeaker string
+	}{
+		{
+			name:        "tag at line start",
+			text:        "[narrator] It was late\n[alice] Who's there",
+			want:        []speakerSpan{{"narrator", "It was late"}, {"alice", "Who's there"}},
+			wantSpeaker: "alice",
+		},
+		{
+			name:        "tag after a sentence",
+			text:        "[narrator] It was late. [alice] Who's there?",
+			want:        []speakerSpan{{"narrator", "It was late."}, {"alice", "Who's there?"}},
+			wantSpeaker: "alice",
+		},
+		{
+			name:        "tag after a quoted sentence",
+			text:        `[bob] He said "stop!" [alice] Why?`,
+			want:        []speakerSpan{{"bob", `He said "stop!"`}, {"alice", "Why?"}},
+			wantSpeaker: "alice",
+		},
+		{
+			name: "index mid-sentence",
+			text: "Read array[index] first.",
+			want: []speakerSpan{{"", "Read array[index] first."}},
+		},
+		{
+			name: "link mid-sentence",
+			text: "See the [link] below.",
+			want: []speakerSpan{{"", "See the [link] below."}},
+		},
+		{
+			name:        "tag mid-sentence",
+			text:        "[bob] Say hi [alice] now",
+			want:        []speakerSpan{{"bob", "Say hi [alice] now"}},
+			wantSpeaker: "bob",
+		},
+		{
+			name:        "tags in a row",
+			text:        "[narrator] [Alice] Hello",
+			want:        []speakerSpan{{"alice", "Hello"}},
+			wantSpeaker: "alice",
+		},
+		{
+			name:        "continues the previous speaker",
+			text:        "Still me.\n[bob] Now me.",
+			speaker:     "alice",
+			want:        []speakerSpan{{"alice", "Still me."}, {"bob", "Now me."}},
+			wantSpeaker: "bob",
+		},
+		{
+			name:        "line breaks within a span",
+			text:        "[alice] One\ntwo.",
+			want:        []speakerSpan{{"alice", "One\ntwo."}},
+			wantSpeaker: "alice",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, speaker := parseSpeakers(test.text, test.speaker)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseSpeakers(%q) = %q, want %q", test.text, got, test.want)
+			}
+			if speaker != test.wantSpeaker {
+				t.Errorf("parseSpeakers(%q) speaker = %q, want %q", test.text, speaker, test.wantSpeaker)
+			}
+		})
+	}
+}
+
+func TestIsSpanStart(t *testing.T) {
+	tests := []struct {
+		before string
+		want   bool
+	}{
+		{"", true},
+		{"It was late\n", true},
+		{"It was late\n  ", true},
+		{"It was late.", true},
+		{"It was late. ", true},
+		{"Who's there? ", true},
+		{`He said "stop!" `, true},
+		{"(see above.) ", true},
+		{"終わり。", true},
+		{"Read array", false},
+		{"See the ", false},
+		{"It was late, ", false},
+	}
+
+	for _, test := range tests {
+		if got := isSpanStart(test.before); got != test.want {
+			t.Errorf("isSpanStart(%q) = %v, want %v", test.before, got, test.want)
+		}
+	}
+}
+
+func TestSpeakerSegments(t *testing.T) {
+	cfg := map[string]interface{}{
+		"AudioCache": false,
+		"Speakers": map[string]interface{}{
+			"alice": map[string]interface{}{"Voice": "alice-voice"},
+		},
+	}
+	req := SpeechRequest{Text: "[narrator] It was\nlate.\n[alice] Who's\tthere?\n[bob] Me."}
+
+	_, segments, err := newSpeakerSynthesizers(req, "fake", inputBlocks(req, cfg), cfg)
+	if err != nil {
+		t.Fatalf("newSpeakerSynthesizers() error = %v", err)
+	}
+
+	var got [][2]string
+	for _, seg := range segments {
+		got = append(got, [2]string{seg.speaker, seg.text})
+	}
+	// Unknown speakers use the default voice
+	want := [][2]string{{"", "It was late."}, {"alice", "Who's there?"}, {"", "Me."}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("segments = %q, want %q", got, want)
+	}
+}
+
+func TestMarkupSegments(t *testing.T) {
+	markup := `<speak>It costs $5. <break time="500ms"/> Really. Yes.</speak>`
+	fake := &fakeSynthesizer{markup: true, limit: 100}
+	cfg := map[string]interface{}{"AudioCache": false, "SpeechChunkSize": 10.0, "FakeSynthesizer": fake}
+
+	// Markup is neither normalized nor split, even over the chunk size
+	req := SpeechRequest{Text: markup}
+	_, segments, err := newSpeakerSynthesizers(req, "fake", inputBlocks(req, cfg), cfg)
+	if err != nil {
+		t.Fatalf("newSpeakerSynthesizers() error = %v", err)
+	}
+	if got := texts(segments); !reflect.DeepEqual(got, []string{markup}) {
+		t.Errorf("segments = %q, want the markup as it is", got)
+	}
+
+	// and rejected over the input limit rather than cut apart
+	fake.limit = 20
+	if _, _, err := newSpeakerSynthesizers(req, "fake", inputBlocks(req, cfg), cfg); ErrorCategory(err) != "invalid_request" {
+		t.Errorf("newSpeakerSynthesizers() error = %v, want invalid_request", err)
+	}
+}
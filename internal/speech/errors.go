@@ -0,0 +1,135 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ln64-git/voxctl/internal/secret"
+)
+
+// ErrorKind categorizes why a provider request failed.
+type ErrorKind string
+
+const (
+	ErrorAuth         ErrorKind = "auth"
+	ErrorQuota        ErrorKind = "quota"
+	ErrorRateLimited  ErrorKind = "rate_limited"
+	ErrorInvalidVoice ErrorKind = "invalid_voice"
+	ErrorTransient    ErrorKind = "transient"
+	ErrorRejected     ErrorKind = "rejected"
+)
+
+// maxErrorBody limits how much of an error response is kept in the message.
+const maxErrorBody = 1024
+
+// ProviderError is returned by providers when a request fails, so callers
+// can tell authentication problems from rate limits or outages.
+type ProviderError struct {
+	Kind       ErrorKind
+	StatusCode int
+	// RetryAfter is the delay requested by the provider, if any
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again.
+func (e *ProviderError) Retryable() bool {
+	return e.Kind == ErrorTransient || e.Kind == ErrorRateLimited
+}
+
+// ResponseError builds a ProviderError from a failed HTTP response,
+// classifying it by status code and body. It reads the body but does not
+// close it.
+func ResponseError(resp *http.Response) error {
+	errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	body := secret.Redact(strings.TrimSpace(string(errorBody)))
+
+	message := fmt.Sprintf("request failed with status: %s", resp.Status)
+	if body != "" {
+		message += ", body: " + body
+	}
+
+	return &ProviderError{
+		Kind:       classifyResponse(resp.StatusCode, body),
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Message:    message,
+	}
+}
+
+// RequestError wraps a failure to reach the provider at all, which is
+// treated as transient. A canceled or expired request context is the
+// caller's doing, so it is returned as such and never retried.
+func RequestError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return &ProviderError{
+		Kind:    ErrorTransient,
+		Message: fmt.Sprintf("failed to send request: %v", err),
+	}
+}
+
+func classifyResponse(statusCode int, body string) ErrorKind {
+	lowerBody := strings.ToLower(body)
+	quotaExhausted := strings.Contains(lowerBody, "quota_exceeded") || strings.Contains(lowerBody, "insufficient_quota")
+
+	switch {
+	case statusCode == http.StatusPaymentRequired || (quotaExhausted && statusCode < 500):
+		return ErrorQuota
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorAuth
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case statusCode == http.StatusNotFound || (statusCode == http.StatusBadRequest && strings.Contains(lowerBody, "voice")):
+		return ErrorInvalidVoice
+	case statusCode == http.StatusRequestTimeout || statusCode >= 500:
+		return ErrorTransient
+	default:
+		return ErrorRejected
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// ErrorCategory names the kind of failure behind err for API responses.
+func ErrorCategory(err error) string {
+	var providerErr *ProviderError
+	var invalidErr *InvalidRequestError
+	var budgetErr *BudgetExceededError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &invalidErr):
+		return "invalid_request"
+	case errors.As(err, &budgetErr):
+		return "budget"
+	case errors.As(err, &providerErr):
+		return string(providerErr.Kind)
+	default:
+		return "internal"
+	}
+}
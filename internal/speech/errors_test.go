@@ -0,0 +1,87 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyResponse(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   ErrorKind
+	}{
+		{401, "", ErrorAuth},
+		{403, "forbidden", ErrorAuth},
+		{402, "", ErrorQuota},
+		{429, `{"error":{"code":"insufficient_quota"}}`, ErrorQuota},
+		{400, `{"detail":{"status":"quota_exceeded"}}`, ErrorQuota},
+		{429, "slow down", ErrorRateLimited},
+		{404, "", ErrorInvalidVoice},
+		{400, "voice not found", ErrorInvalidVoice},
+		{400, "text too long", ErrorRejected},
+		{422, "", ErrorRejected},
+		{408, "", ErrorTransient},
+		{500, "", ErrorTransient},
+		{503, "quota_exceeded", ErrorTransient},
+	}
+
+	for _, test := range tests {
+		if got := classifyResponse(test.status, test.body); got != test.want {
+			t.Errorf("classifyResponse(%d, %q) = %s, want %s", test.status, test.body, got, test.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"0", 0},
+		{"-5", 0},
+		{"soon", 0},
+		{"Fri, 03 May 2024 12:01:30 GMT", 90 * time.Second},
+		{"Fri, 03 May 2024 11:59:00 GMT", 0},
+	}
+
+	for _, test := range tests {
+		if got := parseRetryAfter(test.value, now); got != test.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	resp := &http.Response{
+		Status:     "429 Too Many Requests",
+		StatusCode: 429,
+		Header:     http.Header{"Retry-After": []string{"7"}},
+		Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 2*maxErrorBody))),
+	}
+
+	err := ResponseError(resp).(*ProviderError)
+	if err.Kind != ErrorRateLimited || err.StatusCode != 429 || err.RetryAfter != 7*time.Second {
+		t.Errorf("ResponseError() = %+v", err)
+	}
+	if len(err.Message) > maxErrorBody+100 {
+		t.Errorf("ResponseError() kept %d bytes of the body", len(err.Message))
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	if got := ErrorCategory(RequestError(fmt.Errorf("dial tcp: connection refused"))); got != string(ErrorTransient) {
+		t.Errorf("connection failure category = %s, want transient", got)
+	}
+	if got := ErrorCategory(RequestError(context.Canceled)); got != "canceled" {
+		t.Errorf("canceled request category = %s, want canceled", got)
+	}
+}
@@ -0,0 +1,313 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/cache"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/usage"
+)
+
+var (
+	cooldownMutex sync.Mutex
+	cooldowns     = make(map[string]time.Time)
+)
+
+type chainLink struct {
+	name        string
+	synthesizer Synthesizer
+	opts        SynthesisOptions
+
+	// defaultVoice and languageVoices apply when opts has no voice
+	defaultVoice   string
+	languageVoices map[string]string
+
+	// cache is nil when AudioCache is disabled
+	cache    *cache.Cache
+	settings string
+
+	// ledger is nil when usage can't be tracked
+	ledger *usage.Ledger
+	budget budget
+
+	retry retryPolicy
+}
+
+func (l chainLink) synthesize(ctx context.Context, text, language string) (Audio, error) {
+	opts := l.opts
+	if opts.Voice == "" {
+		opts.Voice = lookupLanguageVoice(l.languageVoices, language)
+	}
+	if opts.Voice == "" {
+		opts.Voice = l.defaultVoice
+	}
+
+	if l.cache == nil {
+		return l.synthesizeUncached(ctx, text, opts)
+	}
+
+	key := audioCacheKey(l.name, l.settings, text, opts)
+	if data, ok := l.cache.Get(key); ok {
+		log.Debugf("Audio cache hit for %s", l.name)
+		return cachedAudio(data), nil
+	}
+
+	audio, err := l.synthesizeUncached(ctx, text, opts)
+	if err != nil {
+		return Audio{}, err
+	}
+	if audio.Stream != nil {
+		audio.Stream = &cacheWriter{ReadCloser: audio.Stream, cache: l.cache, key: key}
+	} else if err := l.cache.Put(key, audio.Data); err != nil {
+		log.Warnf("Failed to cache audio: %v", err)
+	}
+	return audio, nil
+}
+
+// synthesizeUncached calls the provider, retrying transient failures and
+// charging the characters against its budget.
+func (l chainLink) synthesizeUncached(ctx context.Context, text string, opts SynthesisOptions) (Audio, error) {
+	release, err := l.reserveCharacters(text)
+	if err != nil {
+		return Audio{}, err
+	}
+
+	audio, err := l.retry.withRetry(ctx, l.name, func() (Audio, error) {
+		if streamer, ok := l.synthesizer.(StreamSynthesizer); ok {
+			return streamer.SynthesizeStream(ctx, text, opts)
+		}
+		return l.synthesizer.Synthesize(ctx, text, opts)
+	})
+	if err != nil {
+		release()
+		return Audio{}, err
+	}
+	return audio, nil
+}
+
+// failoverSynthesizer tries the primary VoiceService and then each entry of
+// VoiceServiceFallback in order until one of them succeeds.
+type failoverSynthesizer struct {
+	links    []chainLink
+	cooldown time.Duration
+}
+
+// newFailoverSynthesizer builds the provider chain for the given primary
+// VoiceService. The request overrides in opts apply to the primary only, since
+// voice names are provider specific; fallbacks keep their configured voices.
+// Fallback providers that cannot be constructed are skipped.
+func newFailoverSynthesizer(primary string, cfg map[string]interface{}, opts SynthesisOptions) (*failoverSynthesizer, error) {
+	if _, err := lookupFactory(primary); err != nil {
+		return nil, &InvalidRequestError{Reason: err.Error()}
+	}
+
+	names := append([]string{primary}, config.GetStringSliceOrDefault(cfg, "VoiceServiceFallback", nil)...)
+	voices := config.GetStringMapOrDefault(cfg, "VoiceServiceFallbackVoices", nil)
+	languageVoices := loadLanguageVoices(cfg)
+	cooldownSeconds := config.GetFloat64OrDefault(cfg, "VoiceServiceCooldownSeconds", 60)
+	audioCache, err := openAudioCache(cfg)
+	if err != nil {
+		log.Warnf("Audio cache unavailable: %v", err)
+	}
+	ledger, err := openUsageLedger()
+	if err != nil {
+		log.Warnf("Usage ledger unavailable: %v", err)
+	}
+	budgets := loadBudgets(cfg)
+	retry := loadRetryPolicy(cfg)
+
+	f := &failoverSynthesizer{
+		cooldown: time.Duration(cooldownSeconds * float64(time.Second)),
+	}
+	seen := make(map[string]bool)
+	var firstErr error
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		synthesizer, err := NewSynthesizer(name, cfg)
+		if err != nil && name == primary && (opts != SynthesisOptions{} || primary != config.GetStringOrDefault(cfg, "VoiceService", "")) {
+			// The request asked for this provider explicitly, so don't hide the failure
+			return nil, err
+		}
+		if err != nil {
+			log.Warnf("Skipping VoiceService %s: %v", name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		link := chainLink{
+			name:           name,
+			synthesizer:    synthesizer,
+			defaultVoice:   voices[name],
+			languageVoices: languageVoices[name],
+			cache:          audioCache,
+			settings:       providerSettings(name, cfg),
+			ledger:         ledger,
+			budget:         budgets[name],
+			retry:          retry,
+		}
+		if name == primary {
+			if opts.Voice == "" {
+				// A language hint picks the voice from LanguageVoices
+				opts.Voice = lookupLanguageVoice(link.languageVoices, opts.Language)
+			}
+			if err := synthesizer.ValidateOptions(opts); err != nil {
+				return nil, &InvalidRequestError{Reason: fmt.Sprintf("%s: %v", name, err)}
+			}
+			link.opts = opts
+		}
+		f.links = append(f.links, link)
+	}
+
+	if len(f.links) == 0 {
+		return nil, firstErr
+	}
+	return f, nil
+}
+
+// synthesize returns the audio for text along with the name of the provider
+// that produced it. A non-empty language selects the voice from
+// LanguageVoices for links without an explicit voice.
+func (f *failoverSynthesizer) synthesize(ctx context.Context, text, language string) (Audio, string, error) {
+	var failures []string
+	var lastErr error
+	for _, link := range f.available() {
+		audio, err := link.synthesize(ctx, text, language)
+		if err == nil {
+			return audio, link.name, nil
+		}
+		if ctx.Err() != nil {
+			// Canceled, not failed, so don't fall back or cool down
+			return Audio{}, "", ctx.Err()
+		}
+
+		var invalidErr *InvalidRequestError
+		if errors.As(err, &invalidErr) {
+			// No other provider will accept the request either
+			return Audio{}, "", err
+		}
+
+		var budgetErr *BudgetExceededError
+		if errors.As(err, &budgetErr) {
+			if !budgetErr.fallback {
+				return Audio{}, "", err
+			}
+			log.Warnf("%v, falling back", err)
+		} else {
+			log.Warnf("VoiceService %s failed: %v", link.name, err)
+			f.startCooldown(link.name, err)
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", link.name, err))
+		lastErr = err
+	}
+
+	if len(failures) == 1 {
+		return Audio{}, "", lastErr
+	}
+	return Audio{}, "", &chainError{failures: failures, last: lastErr}
+}
+
+// voiceLanguage returns the language of the voice the chain speaks with
+// when no language is asked for, or "" if it isn't known.
+func (f *failoverSynthesizer) voiceLanguage() string {
+	link := f.links[0]
+	switch {
+	case link.opts.Voice != "":
+		return LanguageFromVoiceName(link.opts.Voice)
+	case link.defaultVoice != "":
+		return LanguageFromVoiceName(link.defaultVoice)
+	}
+	if reporter, ok := link.synthesizer.(VoiceLanguager); ok {
+		return reporter.VoiceLanguage()
+	}
+	return ""
+}
+
+// takesMarkup reports whether a provider of the chain takes SSML markup.
+func (f *failoverSynthesizer) takesMarkup() bool {
+	for _, link := range f.links {
+		if markup, ok := link.synthesizer.(MarkupSynthesizer); ok && markup.TakesMarkup() {
+			return true
+		}
+	}
+	return false
+}
+
+// maxInputBytes returns the smallest input limit of the chain, so text
+// fits whichever provider ends up speaking it, or 0 if there is none.
+func (f *failoverSynthesizer) maxInputBytes() int {
+	limit := 0
+	for _, link := range f.links {
+		limiter, ok := link.synthesizer.(InputLimiter)
+		if ok && (limit == 0 || limiter.MaxInputBytes() < limit) {
+			limit = limiter.MaxInputBytes()
+		}
+	}
+	return limit
+}
+
+// chainError reports that every provider in the chain failed. It unwraps to
+// the last failure, which decides the error category.
+type chainError struct {
+	failures []string
+	last     error
+}
+
+func (e *chainError) Error() string {
+	return "all voice services failed: " + strings.Join(e.failures, "; ")
+}
+
+func (e *chainError) Unwrap() error {
+	return e.last
+}
+
+// available returns the links that are not cooling down after a recent
+// failure. If every provider is cooling down, all of them are tried anyway.
+func (f *failoverSynthesizer) available() []chainLink {
+	cooldownMutex.Lock()
+	defer cooldownMutex.Unlock()
+
+	now := time.Now()
+	var links []chainLink
+	for _, link := range f.links {
+		if until, ok := cooldowns[link.name]; ok && now.Before(until) {
+			log.Infof("VoiceService %s is cooling down until %s", link.name, until.Format(time.TimeOnly))
+			continue
+		}
+		links = append(links, link)
+	}
+
+	if len(links) == 0 {
+		return f.links
+	}
+	return links
+}
+
+// startCooldown skips the provider for the configured cooldown, or for as
+// long as a rate limit asked if that is longer.
+func (f *failoverSynthesizer) startCooldown(name string, err error) {
+	if f.cooldown <= 0 || len(f.links) < 2 {
+		return
+	}
+
+	cooldown := f.cooldown
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.RetryAfter > cooldown {
+		cooldown = providerErr.RetryAfter
+	}
+
+	cooldownMutex.Lock()
+	defer cooldownMutex.Unlock()
+	cooldowns[name] = time.Now().Add(cooldown)
+}
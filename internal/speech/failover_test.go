@@ -0,0 +1,128 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// resetCooldowns forgets the cooldowns left by earlier tests.
+func resetCooldowns() {
+	cooldownMutex.Lock()
+	defer cooldownMutex.Unlock()
+	cooldowns = make(map[string]time.Time)
+}
+
+func newTestChain(retry retryPolicy, fakes ...*fakeSynthesizer) *failoverSynthesizer {
+	f := &failoverSynthesizer{cooldown: time.Minute}
+	for i, fake := range fakes {
+		f.links = append(f.links, chainLink{name: string(rune('a' + i)), synthesizer: fake, retry: retry})
+	}
+	return f
+}
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantProvider string
+		wantErr      string
+	}{
+		{name: "auth", err: &ProviderError{Kind: ErrorAuth, StatusCode: 401}, wantProvider: "b"},
+		{name: "rate limited", err: &ProviderError{Kind: ErrorRateLimited, StatusCode: 429}, wantProvider: "b"},
+		{name: "server error", err: &ProviderError{Kind: ErrorTransient, StatusCode: 503}, wantProvider: "b"},
+		{name: "quota", err: &ProviderError{Kind: ErrorQuota, StatusCode: 402}, wantProvider: "b"},
+		{name: "invalid request", err: &InvalidRequestError{Reason: "bad"}, wantErr: "invalid_request"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetCooldowns()
+			primary := &fakeSynthesizer{errs: []error{test.err}}
+			fallback := &fakeSynthesizer{}
+			chain := newTestChain(retryPolicy{}, primary, fallback)
+
+			_, provider, err := chain.synthesize(context.Background(), "Hello.", "")
+			if test.wantErr != "" {
+				if ErrorCategory(err) != test.wantErr {
+					t.Errorf("synthesize() error = %v, want %s", err, test.wantErr)
+				}
+				if calls := fallback.calls(); len(calls) != 0 {
+					t.Errorf("fallback called with %q", calls)
+				}
+				return
+			}
+			if err != nil || provider != test.wantProvider {
+				t.Errorf("synthesize() = %q, %v, want %q", provider, err, test.wantProvider)
+			}
+		})
+	}
+}
+
+func TestFailoverCooldown(t *testing.T) {
+	resetCooldowns()
+	primary := &fakeSynthesizer{errs: []error{&ProviderError{Kind: ErrorTransient, StatusCode: 500}}}
+	fallback := &fakeSynthesizer{}
+	chain := newTestChain(retryPolicy{}, primary, fallback)
+
+	for i := 0; i < 2; i++ {
+		if _, provider, err := chain.synthesize(context.Background(), "Hello.", ""); err != nil || provider != "b" {
+			t.Fatalf("synthesize() = %q, %v, want b", provider, err)
+		}
+	}
+	// The second request skips the primary while it cools down
+	if calls := primary.calls(); len(calls) != 1 {
+		t.Errorf("primary called %d times, want 1", len(calls))
+	}
+}
+
+func TestFailoverAllCoolingDown(t *testing.T) {
+	resetCooldowns()
+	chain := newTestChain(retryPolicy{}, &fakeSynthesizer{}, &fakeSynthesizer{})
+	chain.startCooldown("a", errors.New("failed"))
+	chain.startCooldown("b", errors.New("failed"))
+
+	if _, provider, err := chain.synthesize(context.Background(), "Hello.", ""); err != nil || provider != "a" {
+		t.Errorf("synthesize() = %q, %v, want a", provider, err)
+	}
+}
+
+func TestFailoverLongRetryAfter(t *testing.T) {
+	resetCooldowns()
+	limited := &ProviderError{Kind: ErrorRateLimited, StatusCode: 429, RetryAfter: 30 * time.Second}
+	primary := &fakeSynthesizer{errs: []error{limited}}
+	chain := newTestChain(retryPolicy{retries: 2, maxDelay: 10 * time.Second}, primary, &fakeSynthesizer{})
+
+	start := time.Now()
+	if _, provider, err := chain.synthesize(context.Background(), "Hello.", ""); err != nil || provider != "b" {
+		t.Fatalf("synthesize() = %q, %v, want b", provider, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("synthesize() waited %s instead of moving on", elapsed)
+	}
+	if calls := primary.calls(); len(calls) != 1 {
+		t.Errorf("primary called %d times, want 1", len(calls))
+	}
+
+	cooldownMutex.Lock()
+	until := cooldowns["a"]
+	cooldownMutex.Unlock()
+	if remaining := time.Until(until); remaining < 25*time.Second {
+		t.Errorf("primary cools down for %s, want the 30s asked for", remaining)
+	}
+}
+
+func TestFailoverAllFail(t *testing.T) {
+	resetCooldowns()
+	chain := newTestChain(retryPolicy{},
+		&fakeSynthesizer{errs: []error{&ProviderError{Kind: ErrorAuth, StatusCode: 401}}},
+		&fakeSynthesizer{errs: []error{&ProviderError{Kind: ErrorTransient, StatusCode: 503}}},
+	)
+
+	_, _, err := chain.synthesize(context.Background(), "Hello.", "")
+	var chainErr *chainError
+	if !errors.As(err, &chainErr) || ErrorCategory(err) != string(ErrorTransient) {
+		t.Errorf("synthesize() error = %v, want a chain error ending in transient", err)
+	}
+}
@@ -0,0 +1,56 @@
+package speech
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/markdown"
+)
+
+// inputBlock is a part of the input that is segmented on its own and
+// followed by pause.
+type inputBlock struct {
+	text  string
+	pause time.Duration
+}
+
+// inputBlocks returns the text of the request as blocks. Plain text is one
+// block that keeps its line breaks, so speaker tags can start a line; its
+// whitespace is collapsed span by span after the tags are parsed. Markdown becomes a block per heading, paragraph, list item, table
+// row and code block, with MarkdownHeadingPauseSeconds (default 0.6) of
+// silence after each heading. MarkdownCodeBlocks decides whether code blocks
+// are announced with MarkdownCodeAnnouncement (the default), skipped or
+// read.
+func inputBlocks(req SpeechRequest, cfg map[string]interface{}) []inputBlock {
+	if !req.Markdown {
+		return []inputBlock{{text: strings.TrimSpace(strings.ReplaceAll(req.Text, "\r\n", "\n"))}}
+	}
+
+	opts := markdown.Options{
+		CodeBlocks:       markdown.CodeMode(config.GetStringOrDefault(cfg, "MarkdownCodeBlocks", string(markdown.CodeAnnounce))),
+		CodeAnnouncement: config.GetStringOrDefault(cfg, "MarkdownCodeAnnouncement", "Code block."),
+	}
+	switch opts.CodeBlocks {
+	case markdown.CodeAnnounce, markdown.CodeSkip, markdown.CodeRead:
+	default:
+		log.Warnf("Unknown MarkdownCodeBlocks %q, announcing code blocks", opts.CodeBlocks)
+		opts.CodeBlocks = markdown.CodeAnnounce
+	}
+	headingPause := time.Duration(config.GetFloat64OrDefault(cfg, "MarkdownHeadingPauseSeconds", 0.6) * float64(time.Second))
+
+	var blocks []inputBlock
+	for _, block := range markdown.Parse(req.Text, opts) {
+		text := SanitizeInput(block.Text)
+		if text == "" {
+			continue
+		}
+		b := inputBlock{text: text}
+		if block.Kind == markdown.Heading {
+			b.pause = headingPause
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
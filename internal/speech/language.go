@@ -0,0 +1,112 @@
+package speech
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/langdetect"
+	"github.com/ln64-git/voxctl/internal/normalize"
+)
+
+// loadLanguageVoices reads the "LanguageVoices" object of the configuration,
+// which maps each provider to a language→voice map. Language keys are
+// lower-cased.
+func loadLanguageVoices(cfg map[string]interface{}) map[string]map[string]string {
+	languageVoices := make(map[string]map[string]string)
+	for provider, value := range config.GetMapOrDefault(cfg, "LanguageVoices", nil) {
+		voiceMap, ok := value.(map[string]interface{})
+		if !ok {
+			log.Warnf("Ignoring LanguageVoices for %s: not an object", provider)
+			continue
+		}
+		languageVoices[provider] = make(map[string]string, len(voiceMap))
+		for language, voice := range voiceMap {
+			if voiceName, ok := voice.(string); ok {
+				languageVoices[provider][strings.ToLower(language)] = voiceName
+			}
+		}
+	}
+	return languageVoices
+}
+
+// lookupLanguageVoice finds the voice for a language tag, trying the full
+// tag first and then its primary subtag, so "de-AT" falls back to "de".
+func lookupLanguageVoice(voices map[string]string, language string) string {
+	if language == "" || voices == nil {
+		return ""
+	}
+	language = strings.ToLower(language)
+	if voice, ok := voices[language]; ok {
+		return voice
+	}
+	primary, _, _ := strings.Cut(language, "-")
+	return voices[primary]
+}
+
+// newLanguageDetector returns a detector for the languages mentioned in
+// LanguageVoices, or nil if LanguageDetection is disabled.
+func newLanguageDetector(cfg map[string]interface{}) *langdetect.Detector {
+	if !config.GetBoolOrDefault(cfg, "LanguageDetection", false) {
+		return nil
+	}
+
+	var languages []string
+	seen := make(map[string]bool)
+	for _, voices := range loadLanguageVoices(cfg) {
+		for language := range voices {
+			primary, _, _ := strings.Cut(language, "-")
+			if !seen[primary] {
+				seen[primary] = true
+				languages = append(languages, primary)
+			}
+		}
+	}
+	return langdetect.NewDetector(languages...)
+}
+
+// detectSegmentLanguages sets the language of each segment that has none.
+// Segments too short or ambiguous to classify inherit the language of the
+// previous segment.
+func detectSegmentLanguages(detector *langdetect.Detector, segments []segment) {
+	previous := ""
+	for i := range segments {
+		if segments[i].language != "" {
+			previous = segments[i].language
+			continue
+		}
+
+		language, confidence := detector.Detect(segments[i].text)
+		if language == "" {
+			language = previous
+		} else {
+			log.Debugf("Detected language %s (%.2f) for: %s", language, confidence, segments[i].text)
+		}
+		segments[i].language = language
+		previous = language
+	}
+}
+
+// normalizeText expands numbers, dates, times, currencies and units in text
+// into words. The language hint of the request or profile decides how, then
+// the detected language, TextNormalizationLanguage and the language of the
+// voice, with English as the last resort. SSML documents are left alone,
+// since their attributes hold numbers.
+func normalizeText(text, language, voiceLanguage string, detector *langdetect.Detector, cfg map[string]interface{}) string {
+	if strings.HasPrefix(text, "<speak") {
+		return text
+	}
+	if language == "" && detector != nil {
+		language, _ = detector.Detect(text)
+	}
+	if language == "" {
+		language = config.GetStringOrDefault(cfg, "TextNormalizationLanguage", "")
+	}
+	if language == "" {
+		language = voiceLanguage
+	}
+	if language == "" {
+		language = "en"
+	}
+	return normalize.Normalize(language, text)
+}
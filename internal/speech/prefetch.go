@@ -0,0 +1,95 @@
+package speech
+
+import "context"
+
+type segmentResult struct {
+	segment
+	audio    Audio
+	provider string
+	err      error
+}
+
+// prefetchSegments synthesizes up to depth segments concurrently and delivers
+// the results in their original order. A segment's slot is released once the
+// consumer receives it, so no more than depth segments are ever in flight or
+// waiting. Canceling ctx stops any further segments from being started and
+// aborts those in flight. Streams of results that are no longer delivered
+// are closed once they arrive, so no provider response is left open.
+func prefetchSegments(ctx context.Context, segments []segment, depth int, synthesize func(context.Context, segment) (Audio, string, error)) <-chan segmentResult {
+	done := ctx.Done()
+	if depth < 1 {
+		depth = 1
+	}
+
+	ordered := make(chan segmentResult)
+	slots := make(chan struct{}, depth)
+	pending := make(chan chan segmentResult, depth)
+
+	go func() {
+		defer close(pending)
+		for _, seg := range segments {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			result := make(chan segmentResult, 1)
+			go func(seg segment) {
+				audio, provider, err := synthesize(ctx, seg)
+				result <- segmentResult{segment: seg, audio: audio, provider: provider, err: err}
+			}(seg)
+
+			select {
+			case pending <- result:
+			case <-done:
+				go func() {
+					discardResult(<-result)
+				}()
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(ordered)
+		for result := range pending {
+			var next segmentResult
+			select {
+			case next = <-result:
+			case <-done:
+				go discardPending(result, pending)
+				return
+			}
+
+			select {
+			case ordered <- next:
+				<-slots
+			case <-done:
+				discardResult(next)
+				go discardPending(nil, pending)
+				return
+			}
+		}
+	}()
+
+	return ordered
+}
+
+// discardPending waits for result, if any, and every result still pending,
+// and closes their streams.
+func discardPending(result chan segmentResult, pending <-chan chan segmentResult) {
+	if result != nil {
+		discardResult(<-result)
+	}
+	for result := range pending {
+		discardResult(<-result)
+	}
+}
+
+// discardResult closes the stream of a result that won't be played.
+func discardResult(result segmentResult) {
+	if result.audio.Stream != nil {
+		result.audio.Stream.Close()
+	}
+}
@@ -0,0 +1,142 @@
+package speech
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStreams hands out streams and counts how many are still open.
+type countingStreams struct {
+	open atomic.Int32
+}
+
+func (c *countingStreams) stream() io.ReadCloser {
+	c.open.Add(1)
+	return &countedStream{streams: c}
+}
+
+type countedStream struct {
+	streams *countingStreams
+	once    sync.Once
+}
+
+func (s *countedStream) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (s *countedStream) Close() error {
+	s.once.Do(func() { s.streams.open.Add(-1) })
+	return nil
+}
+
+// waitForClosed fails the test if streams are still open after a second.
+func waitForClosed(t *testing.T, streams *countingStreams) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for streams.open.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d streams left open", streams.open.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPrefetchClosesStreamsAfterCancel(t *testing.T) {
+	segments := make([]segment, 6)
+	streams := &countingStreams{}
+	var calls sync.WaitGroup
+	synthesize := func(ctx context.Context, seg segment) (Audio, string, error) {
+		calls.Add(1)
+		defer calls.Done()
+		// The stream arrives after the cancel, as a response whose headers
+		// were already received would
+		time.Sleep(20 * time.Millisecond)
+		return Audio{Stream: streams.stream()}, "fake", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := prefetchSegments(ctx, segments, 3, synthesize)
+	first := <-results
+	first.audio.Stream.Close()
+	cancel()
+
+	for result := range results {
+		if result.audio.Stream != nil {
+			result.audio.Stream.Close()
+		}
+	}
+	calls.Wait()
+	waitForClosed(t, streams)
+}
+
+func TestPrefetchOrder(t *testing.T) {
+	var segments []segment
+	for i := 0; i < 6; i++ {
+		segments = append(segments, segment{text: strconv.Itoa(i)})
+	}
+	synthesize := func(ctx context.Context, seg segment) (Audio, string, error) {
+		// Later segments finish first
+		index, _ := strconv.Atoi(seg.text)
+		time.Sleep(time.Duration(len(segments)-index) * 5 * time.Millisecond)
+		return Audio{Data: []byte(seg.text)}, "fake", nil
+	}
+
+	for _, depth := range []int{0, 1, 3, 6} {
+		var got []string
+		for result := range prefetchSegments(context.Background(), segments, depth, synthesize) {
+			got = append(got, string(result.audio.Data))
+		}
+		if want := texts(segments); !reflect.DeepEqual(got, want) {
+			t.Errorf("prefetchSegments(depth %d) delivered %q, want %q", depth, got, want)
+		}
+	}
+}
+
+func TestPrefetchDepth(t *testing.T) {
+	tests := []struct {
+		depth int
+		want  int32
+	}{
+		{0, 1},
+		{1, 1},
+		{3, 3},
+		{10, 5},
+	}
+
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.depth), func(t *testing.T) {
+			segments := make([]segment, 5)
+			var started atomic.Int32
+			synthesize := func(ctx context.Context, seg segment) (Audio, string, error) {
+				started.Add(1)
+				return Audio{}, "fake", nil
+			}
+
+			// Without a consumer no more than depth segments are started
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			results := prefetchSegments(ctx, segments, test.depth, synthesize)
+			time.Sleep(20 * time.Millisecond)
+			if got := started.Load(); got != test.want {
+				t.Fatalf("started %d segments before any was received, want %d", got, test.want)
+			}
+
+			// and receiving one makes room for the next
+			<-results
+			deadline := time.Now().Add(time.Second)
+			want := min(test.want+1, int32(len(segments)))
+			for started.Load() != want {
+				if time.Now().After(deadline) {
+					t.Fatalf("started %d segments after one was received, want %d", started.Load(), want)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/config"
+)
+
+// retryBaseDelay is the backoff before the first retry. It doubles with
+// each further attempt.
+const retryBaseDelay = 500 * time.Millisecond
+
+// retryPolicy decides whether and when a failed provider request is sent
+// again.
+type retryPolicy struct {
+	retries  int
+	maxDelay time.Duration
+}
+
+// loadRetryPolicy reads VoiceServiceRetries, the number of retries after
+// the first attempt, and VoiceServiceMaxRetryDelaySeconds, the longest the
+// server waits before a retry. A rate limit asking for a longer wait fails
+// over to the next provider instead.
+func loadRetryPolicy(cfg map[string]interface{}) retryPolicy {
+	return retryPolicy{
+		retries:  int(config.GetFloat64OrDefault(cfg, "VoiceServiceRetries", 2)),
+		maxDelay: time.Duration(config.GetFloat64OrDefault(cfg, "VoiceServiceMaxRetryDelaySeconds", 10) * float64(time.Second)),
+	}
+}
+
+// delay returns how long to wait before retrying after err on the given
+// attempt, counting from zero. The boolean is false if err should not be
+// retried.
+func (p retryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var providerErr *ProviderError
+	if attempt >= p.retries || !errors.As(err, &providerErr) || !providerErr.Retryable() {
+		return 0, false
+	}
+
+	// Equal jitter, half of the backoff fixed and half random, keeps
+	// concurrent prefetches from retrying in lockstep
+	backoff := retryBaseDelay << attempt
+	if backoff > p.maxDelay {
+		backoff = p.maxDelay
+	}
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+	if providerErr.RetryAfter > delay {
+		delay = providerErr.RetryAfter
+	}
+	if delay > p.maxDelay {
+		return 0, false
+	}
+	return delay, true
+}
+
+// withRetry calls attempt until it succeeds, the policy gives up or ctx is
+// canceled.
+func (p retryPolicy) withRetry(ctx context.Context, name string, attempt func() (Audio, error)) (Audio, error) {
+	for try := 0; ; try++ {
+		audio, err := attempt()
+		if err == nil {
+			return audio, nil
+		}
+		if ctx.Err() != nil {
+			return Audio{}, ctx.Err()
+		}
+
+		delay, ok := p.delay(try, err)
+		if !ok {
+			return Audio{}, err
+		}
+		log.Warnf("VoiceService %s failed, retrying in %s: %v", name, delay.Round(time.Millisecond), err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return Audio{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{retries: 3, maxDelay: time.Second}
+	transient := &ProviderError{Kind: ErrorTransient}
+	tests := []struct {
+		name     string
+		attempt  int
+		err      error
+		min, max time.Duration
+		retry    bool
+	}{
+		{name: "first retry", attempt: 0, err: transient, min: 250 * time.Millisecond, max: 500 * time.Millisecond, retry: true},
+		{name: "backoff doubles", attempt: 1, err: transient, min: 500 * time.Millisecond, max: time.Second, retry: true},
+		{name: "backoff is capped", attempt: 2, err: transient, min: 500 * time.Millisecond, max: time.Second, retry: true},
+		{name: "out of retries", attempt: 3, err: transient},
+		{name: "not retryable", attempt: 0, err: &ProviderError{Kind: ErrorAuth}},
+		{name: "not a provider error", attempt: 0, err: errors.New("boom")},
+		{
+			name:    "retry after",
+			attempt: 0,
+			err:     &ProviderError{Kind: ErrorRateLimited, RetryAfter: 800 * time.Millisecond},
+			min:     800 * time.Millisecond,
+			max:     800 * time.Millisecond,
+			retry:   true,
+		},
+		{name: "retry after over the cap", attempt: 0, err: &ProviderError{Kind: ErrorRateLimited, RetryAfter: 5 * time.Second}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				delay, retry := policy.delay(test.attempt, test.err)
+				if retry != test.retry {
+					t.Fatalf("delay() retry = %v, want %v", retry, test.retry)
+				}
+				if retry && (delay < test.min || delay > test.max) {
+					t.Fatalf("delay() = %s, want between %s and %s", delay, test.min, test.max)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	policy := retryPolicy{retries: 2, maxDelay: time.Millisecond}
+	fake := &fakeSynthesizer{errs: []error{&ProviderError{Kind: ErrorTransient}, &ProviderError{Kind: ErrorTransient}}}
+
+	_, err := policy.withRetry(context.Background(), "fake", func() (Audio, error) {
+		return fake.Synthesize(context.Background(), "Hello.", SynthesisOptions{})
+	})
+	if err != nil {
+		t.Errorf("withRetry() error = %v", err)
+	}
+	if calls := fake.calls(); len(calls) != 3 {
+		t.Errorf("called %d times, want 3", len(calls))
+	}
+}
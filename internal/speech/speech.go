@@ -1,19 +1,46 @@
 package speech
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/ln64-git/voxctl/external/azure"
-	"github.com/ln64-git/voxctl/external/elevenLabs"
-	"github.com/ln64-git/voxctl/external/google"
+	"github.com/ln64-git/voxctl/internal/config"
 	"github.com/ln64-git/voxctl/internal/types"
 )
 
-// SpeechRequest represents a request to synthesize speech.
+// SpeechRequest represents a request to synthesize speech. Everything but
+// Text is an optional override of the configured provider and voice.
 type SpeechRequest struct {
-	Text string `json:"text"`
+	Text     string  `json:"text"`
+	Provider string  `json:"provider,omitempty"`
+	Voice    string  `json:"voice,omitempty"`
+	Language string  `json:"language,omitempty"`
+	Gender   string  `json:"gender,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
+
+	// Normalize overrides TextNormalization for this request
+	Normalize *bool `json:"normalize,omitempty"`
+
+	// Markdown reads Text as a markdown document rather than plain text
+	Markdown bool `json:"markdown,omitempty"`
+}
+
+// InvalidRequestError reports a speech request that cannot be honoured as
+// given, such as an unknown provider or an unsupported override.
+type InvalidRequestError struct {
+	Reason string
+}
+
+func (e *InvalidRequestError) Error() string {
+	return "invalid speech request: " + e.Reason
+}
+
+func invalidRequestf(format string, args ...interface{}) error {
+	return &InvalidRequestError{Reason: fmt.Sprintf(format, args...)}
 }
 
 // SanitizeInput removes unwanted characters from a string.
@@ -29,75 +56,138 @@ func SanitizeInput(input string) string {
 	return input
 }
 
-// SpeechRequestToJSON converts a SpeechRequest to a JSON string.
+// SpeechRequestToJSON converts a SpeechRequest to a JSON string. The text
+// keeps its line breaks, since they delimit markdown blocks and speaker
+// tags; the server collapses whitespace once it has parsed them.
 func (r SpeechRequest) SpeechRequestToJSON() string {
-	sanitizedText := SanitizeInput(r.Text)
-	return fmt.Sprintf(`{"text":"%s"}`, sanitizedText)
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		log.Errorf("Failed to marshal speech request: %v", err)
+		return "{}"
+	}
+	return string(jsonData)
 }
 
-// ProcessSpeech processes the speech request by synthesizing and playing the speech.
-func ProcessSpeech(req SpeechRequest, state types.AppState) error {
-	sanitizedText := SanitizeInput(req.Text)
-	segments := getSegmentedText(sanitizedText)
-
-	if state.VoiceService == "ElevenLabs" {
-		for _, segment := range segments {
-			voiceSettings := elevenLabs.VoiceSettings{
-				Stability:       state.ElevenLabsVoiceStability,
-				SimilarityBoost: state.ElevenLabsVoiceSimilarityBoost,
-				Style:           state.ElevenLabsVoiceStyle,
-				UseSpeakerBoost: state.ElevenLabsVoiceUseSpeakerBoost,
-			}
-			audioData, err := elevenLabs.SynthesizeSpeech(state.ElevenLabsSubscriptionKey, state.ElevenLabsVoiceModelID, segment, voiceSettings)
-			if err != nil {
-				log.Errorf("Failed to synthesize speech with ElevenLabs: %v", err)
-				return err
-			}
-			state.AudioPlayer.Play(audioData)
-			log.Infof("Speech processed: %s", segment)
+// options validates the overrides of the request and returns them as
+// SynthesisOptions.
+func (r SpeechRequest) options() (SynthesisOptions, error) {
+	opts := SynthesisOptions{
+		Voice:    strings.TrimSpace(r.Voice),
+		Language: strings.TrimSpace(r.Language),
+		Gender:   strings.ToLower(strings.TrimSpace(r.Gender)),
+		Speed:    r.Speed,
+	}
+
+	if strings.TrimSpace(r.Text) == "" {
+		return opts, invalidRequestf("text is empty")
+	}
+	switch opts.Gender {
+	case "", "male", "female", "neutral":
+	default:
+		return opts, invalidRequestf("gender must be male, female or neutral, got %q", r.Gender)
+	}
+	if opts.Speed != 0 && (opts.Speed < 0.25 || opts.Speed > 4.0) {
+		return opts, invalidRequestf("speed must be between 0.25 and 4.0, got %v", opts.Speed)
+	}
+	if opts.Language != "" && !isLanguageTag(opts.Language) {
+		return opts, invalidRequestf("language %q is not a valid language tag", opts.Language)
+	}
+	if opts.Language != "" && opts.Voice != "" {
+		if voiceLanguage := LanguageFromVoiceName(opts.Voice); voiceLanguage != "" && !strings.EqualFold(voiceLanguage, opts.Language) {
+			return opts, invalidRequestf("voice %s does not speak %s", opts.Voice, opts.Language)
 		}
-	} else if state.VoiceService == "Azure" {
-		for _, segment := range segments {
-			audioData, err := azure.SynthesizeSpeech(state.AzureSubscriptionKey, state.AzureRegion, segment, state.AzureVoiceGender, state.AzureVoiceName)
-			if err != nil {
-				log.Errorf("Failed to synthesize speech with Azure: %v", err)
-				return err
-			}
-			state.AudioPlayer.Play(audioData)
-			log.Infof("Speech processed: %s", segment)
+	}
+	return opts, nil
+}
+
+// isLanguageTag checks for a BCP 47 style tag such as "de" or "en-US".
+func isLanguageTag(tag string) bool {
+	for i, part := range strings.Split(tag, "-") {
+		if len(part) < 2 || len(part) > 8 || (i == 0 && len(part) > 3) {
+			return false
 		}
-	} else if state.VoiceService == "Google" {
-		for _, segment := range segments {
-			audioData, err := google.SynthesizeSpeech(state.GoogleSubscriptionKey, segment, state.GoogleLanguageCode, state.GoogleVoiceName)
-			if err != nil {
-				log.Errorf("Failed to synthesize speech with Google: %v", err)
-				return err
+		for _, char := range part {
+			isLetter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+			if !isLetter && (i == 0 || char < '0' || char > '9') {
+				return false
 			}
-			state.AudioPlayer.Play(audioData)
-			log.Infof("Speech processed: %s", segment)
 		}
-	} else {
-		log.Info("No valid VoiceService found in state")
 	}
-	return nil
+	return true
 }
 
-// getSegmentedText splits text into segments based on punctuation.
-func getSegmentedText(text string) []string {
-	var sentences []string
-	var currentSentence string
-	for i, char := range text {
-		if char == ',' || char == '.' || char == '!' || char == '?' {
-			if currentSentence != "" {
-				sentences = append(sentences, currentSentence)
-				currentSentence = ""
-			}
+// ProcessSpeech processes the speech request by synthesizing and playing the speech.
+// Canceling ctx stops synthesis of the remaining segments and aborts
+// provider requests in flight.
+func ProcessSpeech(ctx context.Context, req SpeechRequest, state types.AppState) error {
+	synthesizers, segments, err := newSpeakerSynthesizers(req, state.VoiceService, inputBlocks(req, state.Config), state.Config)
+	if err != nil {
+		return err
+	}
+	if err := synthesizers.checkBudgets(segments); err != nil {
+		return err
+	}
+	depth := int(config.GetFloat64OrDefault(state.Config, "SpeechPrefetchDepth", 2))
+
+	// Canceling on return stops prefetches that are no longer needed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := prefetchSegments(ctx, segments, depth, synthesizers.synthesize)
+	// stopPrefetch cancels the segments still being synthesized and closes
+	// the streams of those already delivered
+	stopPrefetch := func() {
+		cancel()
+		for result := range results {
+			discardResult(result)
+		}
+	}
+
+	start := time.Now()
+	var totalGap time.Duration
+	played := 0
+	for result := range results {
+		if ctx.Err() != nil {
+			discardResult(result)
+			stopPrefetch()
+			break
+		}
+		if result.err != nil {
+			log.Errorf("Failed to synthesize speech: %v", result.err)
+			stopPrefetch()
+			return result.err
+		}
+
+		if played == 0 {
+			log.Infof("Time to first audio: %s", time.Since(start))
+		} else if idleSince, idle := state.AudioPlayer.IdleSince(); idle {
+			gap := time.Since(idleSince)
+			totalGap += gap
+			log.Infof("Playback gap of %s before segment %d", gap, played+1)
+		}
+
+		if result.audio.Stream != nil {
+			state.AudioPlayer.PlayStream(result.audio.Stream)
 		} else {
-			currentSentence += string(char)
-			if i == len(text)-1 && currentSentence != "" {
-				sentences = append(sentences, currentSentence)
-			}
+			state.AudioPlayer.Play(result.audio.Data)
+		}
+		played++
+		if result.speaker != "" {
+			log.Infof("Speech processed with %s as %s: %s", result.provider, result.speaker, result.text)
+		} else {
+			log.Infof("Speech processed with %s: %s", result.provider, result.text)
 		}
+
+		// The silence after a heading is queued like audio, so it doesn't
+		// hold up synthesis or count as a playback gap
+		state.AudioPlayer.PlaySilence(result.pause)
+	}
+
+	if err := ctx.Err(); err != nil {
+		log.Infof("Speech request canceled after %d of %d segments", played, len(segments))
+		return err
 	}
-	return sentences
+
+	log.Infof("Speech request finished: %d segments in %s, total playback gap %s", played, time.Since(start), totalGap)
+	return nil
 }
@@ -0,0 +1,189 @@
+package speech
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ln64-git/voxctl/internal/audio"
+	"github.com/ln64-git/voxctl/internal/types"
+)
+
+// TestMain keeps the audio cache and usage ledger out of the user's
+// directories.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "voxctl-speech")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("HOME", dir)
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func init() {
+	Register("fake", func(cfg map[string]interface{}) (Synthesizer, error) {
+		if fake, ok := cfg["FakeSynthesizer"].(*fakeSynthesizer); ok {
+			return fake, nil
+		}
+		return &fakeSynthesizer{}, nil
+	})
+}
+
+// fakeSynthesizer speaks text as its own bytes and records what it was
+// asked for. It fails with errs in turn before it succeeds, and always
+// fails for the texts in failures. With streams set it returns streams.
+// markup and limit are what it reports to take.
+type fakeSynthesizer struct {
+	mutex    sync.Mutex
+	texts    []string
+	errs     []error
+	failures map[string]error
+	streams  *countingStreams
+	markup   bool
+	limit    int
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string, opts SynthesisOptions) (Audio, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.texts = append(f.texts, text)
+	if err, ok := f.failures[text]; ok {
+		return Audio{}, err
+	}
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return Audio{}, err
+	}
+	if f.streams != nil {
+		return Audio{Stream: f.streams.stream(), Format: FormatMP3}, nil
+	}
+	return Audio{Data: []byte(text), Format: FormatMP3}, nil
+}
+
+func (f *fakeSynthesizer) ValidateOptions(opts SynthesisOptions) error {
+	return nil
+}
+
+func (f *fakeSynthesizer) TakesMarkup() bool {
+	return f.markup
+}
+
+func (f *fakeSynthesizer) MaxInputBytes() int {
+	return f.limit
+}
+
+func (f *fakeSynthesizer) calls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.texts...)
+}
+
+func TestProcessSpeechClosesStreamsOnError(t *testing.T) {
+	streams := &countingStreams{}
+	fake := &fakeSynthesizer{
+		failures: map[string]error{"One.": &ProviderError{Kind: ErrorRejected, Message: "rejected"}},
+		streams:  streams,
+	}
+	state := types.AppState{
+		VoiceService: "fake",
+		AudioPlayer:  audio.NewAudioPlayer(),
+		Config: map[string]interface{}{
+			"AudioCache":          false,
+			"SpeechChunkSize":     1.0,
+			"SpeechPrefetchDepth": 3.0,
+			"FakeSynthesizer":     fake,
+		},
+	}
+
+	err := ProcessSpeech(context.Background(), SpeechRequest{Text: "One. Two. Three. Four."}, state)
+	if ErrorCategory(err) != string(ErrorRejected) {
+		t.Fatalf("ProcessSpeech() error = %v, want the rejection", err)
+	}
+	waitForClosed(t, streams)
+}
+
+func TestProcessSpeechNormalizesByDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SpeechRequest
+		want []string
+	}{
+		{
+			name: "english by default",
+			req:  SpeechRequest{Text: "14:30 on 2024-05-03, $1,200.50"},
+			want: []string{"fourteen thirty on May third, twenty twenty-four, one thousand two hundred dollars and fifty cents"},
+		},
+		{
+			name: "language of the voice",
+			req:  SpeechRequest{Text: "Es kostet 12,50 €.", Voice: "de-DE-KatjaNeural"},
+			want: []string{"Es kostet zwölf Euro fünfzig."},
+		},
+		{
+			name: "turned off for the request",
+			req:  SpeechRequest{Text: "14:30", Normalize: new(bool)},
+			want: []string{"14:30"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeSynthesizer{}
+			state := types.AppState{
+				VoiceService: "fake",
+				AudioPlayer:  audio.NewAudioPlayer(),
+				// Defaults but for the cache, which would answer repeated runs
+				Config: map[string]interface{}{"AudioCache": false, "FakeSynthesizer": fake},
+			}
+			if err := ProcessSpeech(context.Background(), test.req, state); err != nil {
+				t.Fatalf("ProcessSpeech() error = %v", err)
+			}
+			if got := fake.calls(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("provider got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SpeechRequest
+		wantErr string
+	}{
+		{"no overrides", SpeechRequest{Text: "Hi"}, ""},
+		{"slowest speed", SpeechRequest{Text: "Hi", Speed: 0.25}, ""},
+		{"fastest speed", SpeechRequest{Text: "Hi", Speed: 4.0}, ""},
+		{"too slow", SpeechRequest{Text: "Hi", Speed: 0.2}, "speed must be between 0.25 and 4.0, got 0.2"},
+		{"too fast", SpeechRequest{Text: "Hi", Speed: 4.5}, "speed must be between 0.25 and 4.0, got 4.5"},
+		{"negative speed", SpeechRequest{Text: "Hi", Speed: -1}, "speed must be between 0.25 and 4.0, got -1"},
+		{"empty text", SpeechRequest{Text: " \n"}, "text is empty"},
+		{"gender", SpeechRequest{Text: "Hi", Gender: " Female "}, ""},
+		{"unknown gender", SpeechRequest{Text: "Hi", Gender: "robot"}, `gender must be male, female or neutral, got "robot"`},
+		{"bad language", SpeechRequest{Text: "Hi", Language: "german"}, `language "german" is not a valid language tag`},
+		{"voice of another language", SpeechRequest{Text: "Hi", Voice: "de-DE-KatjaNeural", Language: "fr-FR"}, "voice de-DE-KatjaNeural does not speak fr-FR"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.req.options()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("options() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != "invalid speech request: "+test.wantErr || ErrorCategory(err) != "invalid_request" {
+				t.Errorf("options() error = %v, want invalid_request %q", err, test.wantErr)
+			}
+		})
+	}
+}
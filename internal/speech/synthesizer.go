@@ -0,0 +1,142 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AudioFormat identifies the encoding of synthesized audio data.
+type AudioFormat string
+
+const (
+	FormatMP3 AudioFormat = "mp3"
+	FormatWAV AudioFormat = "wav"
+)
+
+// Audio holds synthesized speech along with its format metadata. Streaming
+// providers set Stream instead of Data; the consumer must close it.
+type Audio struct {
+	Data   []byte
+	Stream io.ReadCloser
+	Format AudioFormat
+}
+
+// SynthesisOptions overrides provider settings for a single call. Empty
+// fields fall back to the provider's configured values. Gender is one of
+// "male", "female" or "neutral"; Speed is a multiplier of the normal rate.
+type SynthesisOptions struct {
+	Voice    string
+	Language string
+	Gender   string
+	Speed    float64
+}
+
+// Synthesizer converts text into audio using a specific voice provider.
+// Canceling ctx aborts the provider request.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string, opts SynthesisOptions) (Audio, error)
+	// ValidateOptions reports overrides the provider cannot honour.
+	ValidateOptions(opts SynthesisOptions) error
+}
+
+// StreamSynthesizer is implemented by providers that can return audio while
+// it is still being generated. When available it is preferred over Synthesize.
+// ctx cancels the request only until the stream is returned, since the stream
+// is still being played after the speech request has finished.
+type StreamSynthesizer interface {
+	SynthesizeStream(ctx context.Context, text string, opts SynthesisOptions) (Audio, error)
+}
+
+// InputLimiter is implemented by providers that reject long input. The limit
+// is in bytes of UTF-8 text escaped for SSML and leaves room for any markup
+// the provider wraps around it. Providers without it accept input of any
+// length.
+type InputLimiter interface {
+	MaxInputBytes() int
+}
+
+// MarkupSynthesizer is implemented by providers that may be configured to
+// take SSML markup rather than plain text. Markup is sent as it is, in one
+// piece, since splitting it into sentences would cut its elements apart.
+type MarkupSynthesizer interface {
+	TakesMarkup() bool
+}
+
+// VoiceLanguager is implemented by providers that know the language of
+// their configured voice, such as "de-DE" for "de-DE-Wavenet-A".
+type VoiceLanguager interface {
+	VoiceLanguage() string
+}
+
+// Factory builds a Synthesizer from the voxctl.json configuration map.
+type Factory func(cfg map[string]interface{}) (Synthesizer, error)
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[string]Factory)
+)
+
+// Register makes a provider available under the given VoiceService name.
+// It is intended to be called from the init function of provider packages.
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if factory == nil {
+		panic("speech: Register factory is nil for " + name)
+	}
+	if _, exists := registry[name]; exists {
+		panic("speech: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Providers returns the sorted names of all registered providers.
+func Providers() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSynthesizer creates the Synthesizer registered under the given name.
+func NewSynthesizer(name string, cfg map[string]interface{}) (Synthesizer, error) {
+	factory, err := lookupFactory(name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(cfg)
+}
+
+func lookupFactory(name string) (Factory, error) {
+	registryMutex.RLock()
+	factory, ok := registry[name]
+	registryMutex.RUnlock()
+
+	if !ok {
+		if name == "" {
+			return nil, fmt.Errorf("no VoiceService configured (available: %s)", strings.Join(Providers(), ", "))
+		}
+		return nil, fmt.Errorf("unknown VoiceService %q (available: %s)", name, strings.Join(Providers(), ", "))
+	}
+	return factory, nil
+}
+
+// LanguageFromVoiceName extracts the language code from a voice name that
+// follows the "de-DE-Wavenet-A" convention, or returns "" if it does not.
+func LanguageFromVoiceName(voiceName string) string {
+	parts := strings.SplitN(voiceName, "-", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[0] + "-" + parts[1]
+}
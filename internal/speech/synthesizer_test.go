@@ -0,0 +1,68 @@
+package speech
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewSynthesizer(t *testing.T) {
+	fake := &fakeSynthesizer{}
+	synthesizer, err := NewSynthesizer("fake", map[string]interface{}{"FakeSynthesizer": fake})
+	if err != nil || synthesizer != fake {
+		t.Fatalf("NewSynthesizer(fake) = %v, %v, want the registered provider", synthesizer, err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "no VoiceService configured (available: "},
+		{"Fake", `unknown VoiceService "Fake" (available: `},
+		{"missing", `unknown VoiceService "missing" (available: `},
+	}
+	for _, test := range tests {
+		_, err := NewSynthesizer(test.name, nil)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) || !strings.Contains(err.Error(), "fake") {
+			t.Errorf("NewSynthesizer(%q) error = %v, want %q listing fake", test.name, err, test.want)
+		}
+	}
+}
+
+func TestProviders(t *testing.T) {
+	factory := func(cfg map[string]interface{}) (Synthesizer, error) { return nil, nil }
+	Register("test-b", factory)
+	Register("test-a", factory)
+	t.Cleanup(func() {
+		registryMutex.Lock()
+		delete(registry, "test-a")
+		delete(registry, "test-b")
+		registryMutex.Unlock()
+	})
+
+	got := strings.Join(Providers(), ",")
+	if !strings.Contains(got, "fake,test-a,test-b") {
+		t.Errorf("Providers() = %s, want the registered names sorted", got)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	factory := func(cfg map[string]interface{}) (Synthesizer, error) { return nil, nil }
+	tests := []struct {
+		name    string
+		factory Factory
+		want    string
+	}{
+		{"fake", factory, "speech: Register called twice for fake"},
+		{"test-nil", nil, "speech: Register factory is nil for test-nil"},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if got := recover(); got != test.want {
+					t.Errorf("Register(%q) panic = %v, want %q", test.name, got, test.want)
+				}
+			}()
+			Register(test.name, test.factory)
+		}()
+	}
+}
@@ -0,0 +1,200 @@
+package speech
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ln64-git/voxctl/internal/cache"
+	"github.com/ln64-git/voxctl/internal/config"
+	"github.com/ln64-git/voxctl/internal/secret"
+)
+
+// Voice describes a voice offered by a provider in a provider-neutral form.
+type Voice struct {
+	Provider string   `json:"provider"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Language string   `json:"language"`
+	Gender   string   `json:"gender"`
+	Styles   []string `json:"styles,omitempty"`
+}
+
+// VoiceLister is implemented by providers that can enumerate their voices.
+type VoiceLister interface {
+	ListVoices() ([]Voice, error)
+}
+
+// VoiceFilter narrows a voice listing. Empty fields match everything.
+type VoiceFilter struct {
+	Provider string
+	Language string
+	Gender   string
+	Refresh  bool
+}
+
+type voiceCatalog struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Voices    []Voice   `json:"voices"`
+}
+
+// ListVoices returns the voices of every configured provider that supports
+// listing, or only filter.Provider if set. Catalogs are cached on disk for
+// VoiceCatalogCacheHours (default 24) unless filter.Refresh is set.
+func ListVoices(cfg map[string]interface{}, filter VoiceFilter) ([]Voice, error) {
+	providers := Providers()
+	if filter.Provider != "" {
+		if _, err := lookupFactory(filter.Provider); err != nil {
+			return nil, &InvalidRequestError{Reason: err.Error()}
+		}
+		providers = []string{filter.Provider}
+	}
+	maxAge := time.Duration(config.GetFloat64OrDefault(cfg, "VoiceCatalogCacheHours", 24) * float64(time.Hour))
+
+	var voices []Voice
+	var failures []string
+	listed := 0
+	for _, name := range providers {
+		catalog, ok, err := loadVoiceCatalog(name, cfg, maxAge, filter.Refresh)
+		if err != nil {
+			if filter.Provider != "" {
+				return nil, err
+			}
+			log.Debugf("Skipping voices of %s: %v", name, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if !ok {
+			if filter.Provider != "" {
+				return nil, &InvalidRequestError{Reason: fmt.Sprintf("%s does not support listing voices", name)}
+			}
+			continue
+		}
+
+		listed++
+		for _, voice := range catalog {
+			if filter.matches(voice) {
+				voices = append(voices, voice)
+			}
+		}
+	}
+
+	if listed == 0 && len(failures) > 0 {
+		return nil, fmt.Errorf("failed to list voices: %s", strings.Join(failures, "; "))
+	}
+
+	sort.Slice(voices, func(i, j int) bool {
+		if voices[i].Provider != voices[j].Provider {
+			return voices[i].Provider < voices[j].Provider
+		}
+		if voices[i].Language != voices[j].Language {
+			return voices[i].Language < voices[j].Language
+		}
+		return voices[i].ID < voices[j].ID
+	})
+	return voices, nil
+}
+
+func (f VoiceFilter) matches(voice Voice) bool {
+	if f.Gender != "" && !strings.EqualFold(f.Gender, voice.Gender) {
+		return false
+	}
+	if f.Language != "" {
+		language := strings.ToLower(voice.Language)
+		wanted := strings.ToLower(f.Language)
+		if language != wanted && !strings.HasPrefix(language, wanted+"-") {
+			return false
+		}
+	}
+	return true
+}
+
+// catalogName names the cached catalog of a provider. Its region and
+// endpoint settings are part of the name, since they decide which voices
+// are offered, and so is a hash of its credentials, since accounts such as
+// ElevenLabs ones offer voices of their own.
+func catalogName(name string, cfg map[string]interface{}) string {
+	settings := fingerprintSettings(name, cfg, func(key string) bool {
+		return strings.Contains(key, "Region") || strings.Contains(key, "Endpoint") || strings.Contains(key, "URL")
+	})
+	settings += credentialFingerprint(name, cfg)
+	if settings == "" {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(name) + "-" + cache.Key(settings)[:12]
+}
+
+// credentialFingerprint hashes the resolved credentials of a provider, so a
+// catalog is not shared between accounts. Credentials that fail to resolve
+// are left out; the provider reports them when it is created.
+func credentialFingerprint(name string, cfg map[string]interface{}) string {
+	var keys []string
+	for key := range cfg {
+		if strings.HasPrefix(key, name) && isCredentialKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var fingerprint strings.Builder
+	for _, key := range keys {
+		if value, err := secret.Get(cfg, key); err == nil && value != "" {
+			fmt.Fprintf(&fingerprint, "%s=%s;", key, cache.Key(value))
+		}
+	}
+	return fingerprint.String()
+}
+
+// loadVoiceCatalog returns the cached catalog of a provider if it is fresh
+// enough, and otherwise fetches and caches a new one. The boolean is false
+// if the provider cannot list its voices.
+func loadVoiceCatalog(name string, cfg map[string]interface{}, maxAge time.Duration, refresh bool) ([]Voice, bool, error) {
+	cacheFile := ""
+	if cacheDir, err := config.GetCacheDir(); err != nil {
+		log.Warnf("Voice catalog cache disabled: %v", err)
+	} else {
+		cacheFile = filepath.Join(cacheDir, "voices-"+catalogName(name, cfg)+".json")
+	}
+
+	if cacheFile != "" && !refresh {
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			var catalog voiceCatalog
+			if err := json.Unmarshal(data, &catalog); err == nil && time.Since(catalog.FetchedAt) < maxAge {
+				return catalog.Voices, true, nil
+			}
+		}
+	}
+
+	synthesizer, err := NewSynthesizer(name, cfg)
+	if err != nil {
+		return nil, false, err
+	}
+	lister, ok := synthesizer.(VoiceLister)
+	if !ok {
+		return nil, false, nil
+	}
+
+	voices, err := lister.ListVoices()
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range voices {
+		voices[i].Provider = name
+	}
+
+	if cacheFile != "" {
+		data, err := json.Marshal(voiceCatalog{FetchedAt: time.Now(), Voices: voices})
+		if err == nil {
+			err = os.WriteFile(cacheFile, data, 0o644)
+		}
+		if err != nil {
+			log.Warnf("Failed to cache voices of %s: %v", name, err)
+		}
+	}
+	return voices, true, nil
+}
@@ -0,0 +1,34 @@
+package speech
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogName(t *testing.T) {
+	base := catalogName("ElevenLabs", map[string]interface{}{"ElevenLabsSubscriptionKey": "first-key"})
+	if !strings.HasPrefix(base, "elevenlabs-") || strings.Contains(base, "first-key") {
+		t.Errorf("catalogName() = %q, want elevenlabs- and a hash", base)
+	}
+
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		same bool
+	}{
+		{"same key", map[string]interface{}{"ElevenLabsSubscriptionKey": "first-key"}, true},
+		{"other settings", map[string]interface{}{"ElevenLabsSubscriptionKey": "first-key", "ElevenLabsVoiceID": "voice"}, true},
+		{"other provider's key", map[string]interface{}{"ElevenLabsSubscriptionKey": "first-key", "AzureSubscriptionKey": "other"}, true},
+		{"other key", map[string]interface{}{"ElevenLabsSubscriptionKey": "second-key"}, false},
+		{"no key", map[string]interface{}{}, false},
+		{"other endpoint", map[string]interface{}{"ElevenLabsSubscriptionKey": "first-key", "ElevenLabsEndpoint": "http://localhost"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := catalogName("ElevenLabs", test.cfg); (got == base) != test.same {
+				t.Errorf("catalogName() = %q, base %q, want same = %v", got, base, test.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const namespace = "http://www.w3.org/2001/10/synthesis"
+
+// EmphasisLevel is the value of the level attribute on <emphasis>.
+type EmphasisLevel string
+
+const (
+	EmphasisStrong   EmphasisLevel = "strong"
+	EmphasisModerate EmphasisLevel = "moderate"
+	EmphasisReduced  EmphasisLevel = "reduced"
+	EmphasisNone     EmphasisLevel = "none"
+)
+
+// BreakStrength is the value of the strength attribute on <break>.
+type BreakStrength string
+
+const (
+	BreakNone    BreakStrength = "none"
+	BreakXWeak   BreakStrength = "x-weak"
+	BreakWeak    BreakStrength = "weak"
+	BreakMedium  BreakStrength = "medium"
+	BreakStrong  BreakStrength = "strong"
+	BreakXStrong BreakStrength = "x-strong"
+)
+
+// Node is a piece of SSML content. Nodes are created with the functions in
+// this package, which escape all text and attribute values.
+type Node interface {
+	render(b *strings.Builder)
+}
+
+// Document is a complete <speak> document.
+type Document struct {
+	Lang     string
+	Children []Node
+}
+
+// Speak creates a document in the given language. An empty lang omits the
+// xml:lang attribute.
+func Speak(lang string, children ...Node) *Document {
+	return &Document{Lang: lang, Children: children}
+}
+
+// Append adds nodes to the end of the document.
+func (d *Document) Append(children ...Node) *Document {
+	d.Children = append(d.Children, children...)
+	return d
+}
+
+// String renders the document as SSML.
+func (d *Document) String() string {
+	var b strings.Builder
+	b.WriteString("<speak")
+	writeAttr(&b, "version", "1.0")
+	writeAttr(&b, "xmlns", namespace)
+	writeAttr(&b, "xml:lang", d.Lang)
+	b.WriteString(">")
+	renderAll(&b, d.Children)
+	b.WriteString("</speak>")
+	return b.String()
+}
+
+// Render renders nodes without a surrounding <speak> element, for providers
+// that only accept a bare root.
+func Render(nodes ...Node) string {
+	var b strings.Builder
+	renderAll(&b, nodes)
+	return b.String()
+}
+
+type text string
+
+func (t text) render(b *strings.Builder) {
+	xml.EscapeText(b, []byte(t))
+}
+
+// Text is plain text with XML special characters escaped.
+func Text(s string) Node {
+	return text(s)
+}
+
+type element struct {
+	name     string
+	attrs    [][2]string
+	children []Node
+}
+
+func (e element) render(b *strings.Builder) {
+	b.WriteString("<")
+	b.WriteString(e.name)
+	for _, attr := range e.attrs {
+		writeAttr(b, attr[0], attr[1])
+	}
+	if len(e.children) == 0 {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	renderAll(b, e.children)
+	b.WriteString("</")
+	b.WriteString(e.name)
+	b.WriteString(">")
+}
+
+// VoiceOptions selects the voice for a <voice> element. Empty fields are
+// omitted.
+type VoiceOptions struct {
+	Name   string
+	Lang   string
+	Gender string
+}
+
+// Voice speaks its children with the given voice.
+func Voice(opts VoiceOptions, children ...Node) Node {
+	return element{
+		name: "voice",
+		attrs: [][2]string{
+			{"xml:lang", opts.Lang},
+			{"xml:gender", opts.Gender},
+			{"name", opts.Name},
+		},
+		children: children,
+	}
+}
+
+// ProsodyOptions holds the rate, pitch and volume of a <prosody> element,
+// using any value the provider accepts (e.g. "slow", "+10%", "-2st", "loud").
+// Empty fields are omitted.
+type ProsodyOptions struct {
+	Rate   string
+	Pitch  string
+	Volume string
+}
+
+// IsZero reports whether no prosody attribute is set.
+func (p ProsodyOptions) IsZero() bool {
+	return p == ProsodyOptions{}
+}
+
+// Prosody changes the rate, pitch or volume of its children.
+func Prosody(opts ProsodyOptions, children ...Node) Node {
+	return element{
+		name: "prosody",
+		attrs: [][2]string{
+			{"rate", opts.Rate},
+			{"pitch", opts.Pitch},
+			{"volume", opts.Volume},
+		},
+		children: children,
+	}
+}
+
+// Break inserts a pause of the given duration.
+func Break(duration time.Duration) Node {
+	return element{
+		name:  "break",
+		attrs: [][2]string{{"time", fmt.Sprintf("%dms", duration.Milliseconds())}},
+	}
+}
+
+// BreakWithStrength inserts a pause of a relative strength.
+func BreakWithStrength(strength BreakStrength) Node {
+	return element{
+		name:  "break",
+		attrs: [][2]string{{"strength", string(strength)}},
+	}
+}
+
+// Emphasis speaks its children with the given level of stress.
+func Emphasis(level EmphasisLevel, children ...Node) Node {
+	return element{
+		name:     "emphasis",
+		attrs:    [][2]string{{"level", string(level)}},
+		children: children,
+	}
+}
+
+// SayAs tells the provider how to interpret text, e.g. interpretAs "date"
+// with format "ymd", or "characters" to spell a word out.
+func SayAs(interpretAs, format, content string) Node {
+	return element{
+		name: "say-as",
+		attrs: [][2]string{
+			{"interpret-as", interpretAs},
+			{"format", format},
+		},
+		children: []Node{Text(content)},
+	}
+}
+
+// Phoneme speaks content using an explicit pronunciation in the given
+// alphabet, such as "ipa" or "x-sampa".
+func Phoneme(alphabet, ph, content string) Node {
+	return element{
+		name: "phoneme",
+		attrs: [][2]string{
+			{"alphabet", alphabet},
+			{"ph", ph},
+		},
+		children: []Node{Text(content)},
+	}
+}
+
+func renderAll(b *strings.Builder, nodes []Node) {
+	for _, node := range nodes {
+		if node != nil {
+			node.render(b)
+		}
+	}
+}
+
+// writeAttr writes a quoted, escaped attribute, skipping empty values.
+func writeAttr(b *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(name)
+	b.WriteString(`="`)
+	xml.EscapeText(b, []byte(value))
+	b.WriteString(`"`)
+}
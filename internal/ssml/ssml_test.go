@@ -0,0 +1,97 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		node Node
+		want string
+	}{
+		{
+			name: "escapes text",
+			node: Text(`Tom & Jerry <3 "quotes" 'too'`),
+			want: `Tom &amp; Jerry &lt;3 &#34;quotes&#34; &#39;too&#39;`,
+		},
+		{
+			name: "escapes attributes",
+			node: Voice(VoiceOptions{Name: `a"><evil/>`}, Text("hi")),
+			want: `<voice name="a&#34;&gt;&lt;evil/&gt;">hi</voice>`,
+		},
+		{
+			name: "omits empty attributes",
+			node: Prosody(ProsodyOptions{Rate: "slow"}, Text("hi")),
+			want: `<prosody rate="slow">hi</prosody>`,
+		},
+		{
+			name: "empty element",
+			node: Break(1500 * time.Millisecond),
+			want: `<break time="1500ms"/>`,
+		},
+		{
+			name: "escapes content of say-as",
+			node: SayAs("characters", "", "a<b"),
+			want: `<say-as interpret-as="characters">a&lt;b</say-as>`,
+		},
+		{
+			name: "escapes phonemes",
+			node: Phoneme("ipa", `təˈmɑːtoʊ"`, "tomato"),
+			want: `<phoneme alphabet="ipa" ph="təˈmɑːtoʊ&#34;">tomato</phoneme>`,
+		},
+		{
+			name: "nested",
+			node: Emphasis(EmphasisStrong, Text("a"), BreakWithStrength(BreakWeak), Text("b")),
+			want: `<emphasis level="strong">a<break strength="weak"/>b</emphasis>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Render(test.node); got != test.want {
+				t.Errorf("Render() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDocument(t *testing.T) {
+	doc := Speak("en-US", Text("a & b")).Append(Break(time.Second))
+	want := `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="en-US">a &amp; b<break time="1000ms"/></speak>`
+	if got := doc.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	if got := Speak("").String(); strings.Contains(got, "xml:lang") {
+		t.Errorf("String() = %s, want no xml:lang", got)
+	}
+}
+
+// TestWellFormed checks that hostile text and attributes still give a
+// document an XML parser accepts, with the text intact.
+func TestWellFormed(t *testing.T) {
+	hostile := `</voice></speak><speak>"it's" & <b>`
+	doc := Speak(hostile, Voice(VoiceOptions{Name: hostile, Lang: hostile, Gender: hostile}, Prosody(ProsodyOptions{Rate: hostile}, Text(hostile))))
+
+	var parsed struct {
+		Voice struct {
+			Name    string `xml:"name,attr"`
+			Prosody struct {
+				Rate string `xml:"rate,attr"`
+				Text string `xml:",chardata"`
+			} `xml:"prosody"`
+		} `xml:"voice"`
+	}
+	if err := xml.Unmarshal([]byte(doc.String()), &parsed); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", doc, err)
+	}
+	for _, got := range []string{parsed.Voice.Name, parsed.Voice.Prosody.Rate, parsed.Voice.Prosody.Text} {
+		if got != hostile {
+			t.Errorf("parsed %q, want %q", got, hostile)
+		}
+	}
+}
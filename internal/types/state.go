@@ -6,37 +6,28 @@ import (
 
 // State struct to hold program state
 type AppState struct {
-	ClientPort  int
-	ClientInput string
+	ClientPort      int
+	ClientInput     string
+	ClientFile      string
+	ClientProvider  string
+	ClientVoice     string
+	ClientLanguage  string
+	ClientGender    string
+	ClientSpeed     float64
+	ClientNormalize string
+	ClientMarkdown  bool
 
 	ServerStatusRequested bool
+	ServerVoicesRequested bool
+	ServerCacheCommand    string
+	ServerUsageRequested  bool
 	ServerQuitRequested   bool
 	ServerPauseRequested  bool
 	ServerStopRequested   bool
 
-	VoiceService                   string
-	ElevenLabsSubscriptionKey      string
-	ElevenLabsVoiceModelID         string
-	ElevenLabsVoiceStability       float64
-	ElevenLabsVoiceSimilarityBoost float64
-	ElevenLabsVoiceStyle           float64
-	ElevenLabsVoiceUseSpeakerBoost bool
-
-	AzureSubscriptionKey string
-	AzureRegion          string
-	AzureVoiceGender     string
-	AzureVoiceName       string
-
-	GoogleSubscriptionKey string
-	GoogleLanguageCode    string
-	GoogleVoiceName       string
+	VoiceService string
+	Config       map[string]interface{}
 
 	AudioPlayer          *audio.AudioPlayer
 	ServerAlreadyRunning bool
 }
-
-type SpeechRequest struct {
-	Text      string `json:"text"`
-	Gender    string `json:"gender"`
-	VoiceName string `json:"voiceName"`
-}
@@ -0,0 +1,155 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dayLayout = "2006-01-02"
+
+// retention is how long daily totals are kept in the ledger.
+const retention = 400 * 24 * time.Hour
+
+// Ledger records the characters sent to each provider per day and persists
+// them to a JSON file.
+type Ledger struct {
+	path  string
+	mutex sync.Mutex
+	days  map[string]map[string]int64
+}
+
+// Totals are the characters sent to a provider in the current day and month.
+type Totals struct {
+	Today int64 `json:"today"`
+	Month int64 `json:"month"`
+}
+
+// Open loads the ledger stored at path. A missing file starts an empty
+// ledger.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{path: path, days: make(map[string]map[string]int64)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %v", err)
+	}
+	if err := json.Unmarshal(data, &l.days); err != nil {
+		return nil, fmt.Errorf("failed to parse usage ledger: %v", err)
+	}
+	return l, nil
+}
+
+// Record adds characters sent to provider at the given time and saves the
+// ledger. A negative count gives back characters reserved for a request that
+// failed.
+func (l *Ledger) Record(provider string, characters int64, at time.Time) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.add(provider, characters, at)
+	return l.save()
+}
+
+// Reserve records characters about to be sent to provider if allow accepts
+// the totals before them. The check and the record happen under one lock,
+// so concurrent requests can't all pass a budget that only fits one of them.
+// An error from allow is returned as is and nothing is recorded.
+func (l *Ledger) Reserve(provider string, characters int64, at time.Time, allow func(Totals) error) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := allow(l.totals(provider, at)); err != nil {
+		return err
+	}
+	l.add(provider, characters, at)
+	return l.save()
+}
+
+func (l *Ledger) add(provider string, characters int64, at time.Time) {
+	day := at.Format(dayLayout)
+	if l.days[day] == nil {
+		l.days[day] = make(map[string]int64)
+	}
+	l.days[day][provider] += characters
+	l.prune(at)
+}
+
+// Totals returns the usage of provider for the day and month of now.
+func (l *Ledger) Totals(provider string, now time.Time) Totals {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.totals(provider, now)
+}
+
+// All returns the usage of every provider in the ledger for the day and
+// month of now.
+func (l *Ledger) All(now time.Time) map[string]Totals {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	providers := make(map[string]bool)
+	for _, day := range l.days {
+		for provider := range day {
+			providers[provider] = true
+		}
+	}
+
+	all := make(map[string]Totals, len(providers))
+	for provider := range providers {
+		all[provider] = l.totals(provider, now)
+	}
+	return all
+}
+
+func (l *Ledger) totals(provider string, now time.Time) Totals {
+	today := now.Format(dayLayout)
+	month := now.Format("2006-01")
+
+	var totals Totals
+	for day, providers := range l.days {
+		if day == today {
+			totals.Today += providers[provider]
+		}
+		if strings.HasPrefix(day, month) {
+			totals.Month += providers[provider]
+		}
+	}
+	return totals
+}
+
+// prune drops days older than the retention period.
+func (l *Ledger) prune(now time.Time) {
+	cutoff := now.Add(-retention).Format(dayLayout)
+	for day := range l.days {
+		if day < cutoff {
+			delete(l.days, day)
+		}
+	}
+}
+
+// save writes the ledger through a temporary file so a crash never leaves a
+// truncated ledger behind. The caller must hold the mutex.
+func (l *Ledger) save() error {
+	data, err := json.MarshalIndent(l.days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage ledger: %v", err)
+	}
+
+	tempFile := l.path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %v", err)
+	}
+	if err := os.Rename(tempFile, l.path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to write usage ledger: %v", err)
+	}
+	return nil
+}
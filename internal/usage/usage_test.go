@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTotals(t *testing.T) {
+	l, err := Open(filepath.Join(t.TempDir(), "usage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(characters int64, at time.Time) {
+		t.Helper()
+		if err := l.Record("Google", characters, at); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	record(100, time.Date(2024, 4, 30, 23, 0, 0, 0, time.Local))
+	record(20, time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local))
+	record(3, time.Date(2024, 5, 2, 9, 0, 0, 0, time.Local))
+	if err := l.Record("Azure", 7, time.Date(2024, 5, 2, 9, 0, 0, 0, time.Local)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want Totals
+	}{
+		{"last day of the month", time.Date(2024, 4, 30, 23, 30, 0, 0, time.Local), Totals{Today: 100, Month: 100}},
+		{"new month", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), Totals{Today: 20, Month: 23}},
+		{"new day", time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local), Totals{Today: 3, Month: 23}},
+		{"later in the month", time.Date(2024, 5, 20, 12, 0, 0, 0, time.Local), Totals{Today: 0, Month: 23}},
+	}
+	for _, test := range tests {
+		if got := l.Totals("Google", test.now); got != test.want {
+			t.Errorf("%s: Totals() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+
+	all := l.All(time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local))
+	if len(all) != 2 || all["Azure"] != (Totals{Today: 7, Month: 7}) {
+		t.Errorf("All() = %+v", all)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.Local)
+	limit := func(max int64, characters int64) func(Totals) error {
+		return func(totals Totals) error {
+			if totals.Today+characters > max {
+				return errors.New("over budget")
+			}
+			return nil
+		}
+	}
+
+	if err := l.Reserve("Google", 8, now, limit(10, 8)); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := l.Reserve("Google", 8, now, limit(10, 8)); err == nil {
+		t.Error("Reserve() over the limit succeeded")
+	}
+	if got := l.Totals("Google", now); got.Today != 8 {
+		t.Errorf("refused reservation was recorded: %+v", got)
+	}
+
+	// A failed request gives its characters back
+	if err := l.Record("Google", -8, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reserve("Google", 8, now, limit(10, 8)); err != nil {
+		t.Errorf("Reserve() after release error = %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Totals("Google", now); got.Today != 8 {
+		t.Errorf("reopened Totals() = %+v, want 8 today", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	l, err := Open(filepath.Join(t.TempDir(), "usage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2022, 1, 1, 12, 0, 0, 0, time.Local)
+	if err := l.Record("Google", 5, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record("Google", 1, old.Add(2*retention)); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Totals("Google", old); got.Today != 0 {
+		t.Errorf("usage older than the retention was kept: %+v", got)
+	}
+}